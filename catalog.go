@@ -0,0 +1,98 @@
+package generate
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Catalog maps URI prefixes, e.g. "https://schemas.example.com/", to the local directories holding the schemas
+// published under them, in the spirit of XML catalogs, so that the schemas are read from disk rather than fetched.
+// A prefix which doesn't end in "/" can also map a single URI to a file.
+type Catalog map[string]string
+
+// ReadCatalog reads a JSON file containing an object of directories keyed by URI prefix, e.g.
+// {"https://schemas.example.com/": "../schemas"}. Relative directories are relative to the catalog file.
+func ReadCatalog(file string) (Catalog, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.New("failed to read the catalog file with error " + err.Error())
+	}
+	var c Catalog
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, errors.New("failed to parse the catalog file " + file + " with error " + err.Error())
+	}
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+	for prefix, p := range c {
+		if !filepath.IsAbs(p) {
+			c[prefix] = filepath.Join(dir, p)
+		}
+	}
+	return c, nil
+}
+
+// Path returns the local path of the document at the URI, or false if none of the prefixes match it.
+func (c Catalog) Path(uri *url.URL) (string, bool) {
+	u := *uri
+	u.Fragment = ""
+	s := u.String()
+	prefix := ""
+	for p := range c {
+		if hasPathPrefix(s, p) && len(p) > len(prefix) {
+			prefix = p
+		}
+	}
+	if prefix == "" {
+		return "", false
+	}
+	rest, err := url.PathUnescape(s[len(prefix):])
+	if err != nil {
+		return "", false
+	}
+	// cleaning the rooted path stops it from leaving the directory
+	rest = path.Clean("/" + rest)
+	return filepath.Join(c[prefix], filepath.FromSlash(rest)), true
+}
+
+// hasPathPrefix returns true when the prefix is a directory containing the URI, or the URI of the file itself, e.g.
+// "https://example.com/a" is a prefix of "https://example.com/a/b.json", but not of "https://example.com/ab.json".
+func hasPathPrefix(s, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	return strings.HasSuffix(prefix, "/") || len(s) == len(prefix) || s[len(prefix)] == '/'
+}
+
+// URI returns the URI of the document at the local path, or false if it isn't within any of the directories.
+func (c Catalog) URI(file string) (string, bool) {
+	prefix, dir := "", ""
+	for p, d := range c {
+		if (file == d || strings.HasPrefix(file, d+string(filepath.Separator))) && len(d) > len(dir) {
+			prefix, dir = p, d
+		}
+	}
+	if dir == "" {
+		return "", false
+	}
+	rest := filepath.ToSlash(file[len(dir):])
+	if strings.HasSuffix(prefix, "/") {
+		rest = strings.TrimPrefix(rest, "/")
+	}
+	return prefix + (&url.URL{Path: rest}).EscapedPath(), true
+}
+
+// Load reads the document at the URI from its local path.
+func (c Catalog) Load(uri *url.URL) ([]byte, error) {
+	file, ok := c.Path(uri)
+	if !ok {
+		return nil, errors.New("the catalog doesn't hold a directory for the URI")
+	}
+	return ioutil.ReadFile(file)
+}
@@ -0,0 +1,98 @@
+package generate
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestThatCatalogsMapURIsToPaths(t *testing.T) {
+	c := Catalog{
+		"https://schemas.example.com/":        filepath.FromSlash("/src/schemas"),
+		"https://schemas.example.com/vendor/": filepath.FromSlash("/src/vendor"),
+		"https://example.com/order.json":      filepath.FromSlash("/src/order.json"),
+	}
+	tests := []struct {
+		uri  string
+		path string
+		ok   bool
+	}{
+		{uri: "https://schemas.example.com/address.json", path: "/src/schemas/address.json", ok: true},
+		{uri: "https://schemas.example.com/common/a.json#/definitions/a", path: "/src/schemas/common/a.json", ok: true},
+		{uri: "https://schemas.example.com/vendor/b.json", path: "/src/vendor/b.json", ok: true},
+		{uri: "https://schemas.example.com/../../etc/passwd", path: "/src/schemas/etc/passwd", ok: true},
+		{uri: "https://schemas.example.com/sales%20order.json", path: "/src/schemas/sales order.json", ok: true},
+		{uri: "https://example.com/order.json", path: "/src/order.json", ok: true},
+		{uri: "https://example.com/order.json2", ok: false},
+		{uri: "https://example.com/other.json", ok: false},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, ok := c.Path(u)
+		if ok != test.ok || path != filepath.FromSlash(test.path) {
+			t.Errorf("%s: expected %q, %v, got %q, %v", test.uri, test.path, test.ok, path, ok)
+		}
+		// the URI of the path is the same, unless it had a fragment, or was cleaned
+		if !ok || u.Fragment != "" || strings.Contains(test.uri, "/../") {
+			continue
+		}
+		if uri, ok := c.URI(path); !ok || uri != test.uri {
+			t.Errorf("%s: expected the URI of %s to be the same, got %q, %v", test.uri, path, uri, ok)
+		}
+	}
+	if uri, ok := c.URI(filepath.FromSlash("/src/schemas2/a.json")); ok {
+		t.Errorf("expected no URI for a path outside of the directories, got %s", uri)
+	}
+}
+
+func TestThatCatalogsAreUsedToReadSchemas(t *testing.T) {
+	dir, err := ioutil.TempDir("", "generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"catalog.json":                `{ "https://schemas.example.com/": "schemas" }`,
+		"schemas/order.json":          `{ "title": "Order", "properties": { "address": { "$ref": "common/address.json" } } }`,
+		"schemas/common/address.json": `{ "title": "Address", "properties": { "country": { "$ref": "https://schemas.example.com/common/country.json" } } }`,
+		"schemas/common/country.json": `{ "title": "Country", "type": "string" }`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	catalog, err := ReadCatalog(filepath.Join(dir, "catalog.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"https://schemas.example.com/order.json", filepath.Join(dir, "schemas", "order.json")} {
+		schemas, err := ReadInputFilesWithCatalog([]string{input}, false, catalog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id := schemas[0].ID(); id != "https://schemas.example.com/order.json" {
+			t.Errorf("%s: expected the schema to be identified by its URI in the catalog, got %s", input, id)
+		}
+
+		g := New(schemas...)
+		g.Catalog = catalog
+		if err := g.CreateTypes(); err != nil {
+			t.Fatal(err)
+		}
+		if g.Structs["Order"].Fields["Address"].Type != "*Address" || g.Structs["Address"].Fields["Country"].Type != "string" {
+			t.Errorf("%s: unexpected types: %+v", input, g.Structs)
+		}
+	}
+}
@@ -3,10 +3,13 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/a-h/generate"
 )
@@ -16,6 +19,21 @@ var (
 	p                     = flag.String("p", "main", "The package that the structs are created in.")
 	i                     = flag.String("i", "", "A single file path (used for backwards compatibility).")
 	schemaKeyRequiredFlag = flag.Bool("schemaKeyRequired", false, "Allow input files with no $schema key.")
+	validateFlag          = flag.Bool("validate", false, "Generate a Validate method for each type, which checks values against the schema's constraints.")
+	typeMappingsFlag      = flag.String("typeMappings", "", "A JSON file containing an array of mappings from schemas to golang types, e.g. [{\"type\": \"number\", \"goType\": \"github.com/shopspring/decimal.Decimal\"}].")
+	preserveOrderFlag     = flag.Bool("preserveOrder", false, "Declare struct fields, and marshal them, in the order the properties appear in the schema rather than alphabetically.")
+	initialismsFlag       = flag.Bool("initialisms", false, "Write the initialisms that golint checks for in upper case in golang names, e.g. \"ID\" rather than \"Id\".")
+	customInitialismsFlag = flag.String("customInitialisms", "", "A comma separated list of initialisms to write in upper case in golang names, in addition to those enabled by -initialisms, e.g. \"SKU,VAT\".")
+	optionalFlag          = flag.String("optional", "values", "How optional and nullable properties are represented: \"values\" (primitive types use values, or pointers when nullable), \"pointers\" for primitive types, \"generic\" for types.Optional, or \"tristate\" for types.Nullable, which tells missing and null values apart. The last two need Go 1.18.")
+	defaultsFlag          = flag.Bool("defaults", false, "Set the properties which are missing from the JSON to their default values when unmarshalling.")
+	inferIntegersFlag     = flag.Bool("inferIntegers", false, "Use the narrowest golang integer type which holds the minimum and maximum of integers without a format, e.g. uint8 for 0 to 255.")
+	bigIntegersFlag       = flag.Bool("bigIntegers", false, "Use *big.Int rather than json.Number for integers whose bounds don't fit in an int64 or uint64.")
+	catalogFlag           = flag.String("catalog", "", "A JSON file mapping URI prefixes to the local directories holding the schemas published under them, e.g. {\"https://schemas.example.com/\": \"../schemas\"}. The schemas are read from there, rather than fetched, and input files can be passed as URIs.")
+	remoteFlag            = flag.Bool("remote", false, "Fetch the schemas referenced over http and https. This is implied by -cacheDir, -offline and -lockfile.")
+	cacheDirFlag          = flag.String("cacheDir", "", "A directory to store the schemas referenced over http and https in, which are read from there rather than fetched again.")
+	offlineFlag           = flag.Bool("offline", false, "Don't fetch the schemas referenced over http and https, but read them from -cacheDir.")
+	lockfileFlag          = flag.String("lockfile", "", "A JSON file holding the checksums of the schemas referenced over http and https. Schemas whose checksums are different are rejected, and the checksums of new schemas are added.")
+	formatsFlag           = flag.Bool("formats", false, "Use golang types for strings with a format, e.g. time.Time for \"date-time\".")
 )
 
 func main() {
@@ -38,13 +56,72 @@ func main() {
 		os.Exit(1)
 	}
 
-	schemas, err := generate.ReadInputFiles(inputFiles, *schemaKeyRequiredFlag)
+	var catalog generate.Catalog
+	if *catalogFlag != "" {
+		var err error
+		catalog, err = generate.ReadCatalog(*catalogFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	schemas, err := generate.ReadInputFilesWithCatalog(inputFiles, *schemaKeyRequiredFlag, catalog)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, err.Error())
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
 	g := generate.New(schemas...)
+	g.Catalog = catalog
+	g.GenerateValidation = *validateFlag
+	g.FormatTypes = *formatsFlag
+	g.PreserveOrder = *preserveOrderFlag
+	g.ApplyDefaults = *defaultsFlag
+	g.InferIntegerTypes = *inferIntegersFlag
+	g.BigIntegers = *bigIntegersFlag
+	switch *optionalFlag {
+	case "values":
+		g.OptionalPolicy = generate.OptionalValues
+	case "pointers":
+		g.OptionalPolicy = generate.OptionalPointers
+	case "generic":
+		g.OptionalPolicy = generate.OptionalGeneric
+	case "tristate":
+		g.OptionalPolicy = generate.OptionalTriState
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown -optional policy %q.\n", *optionalFlag)
+		os.Exit(1)
+	}
+	if *initialismsFlag {
+		g.Initialisms = append(g.Initialisms, generate.CommonInitialisms...)
+	}
+	if *customInitialismsFlag != "" {
+		g.Initialisms = append(g.Initialisms, strings.Split(*customInitialismsFlag, ",")...)
+	}
+	if *typeMappingsFlag != "" {
+		g.TypeMappings, err = generate.ReadTypeMappings(*typeMappingsFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	// schemas are only fetched when asked to, so that generating code doesn't depend on the network by surprise
+	loader := &generate.HTTPLoader{
+		CacheDir: *cacheDirFlag,
+		Offline:  *offlineFlag,
+	}
+	if *lockfileFlag != "" {
+		loader.Lockfile, err = generate.ReadLockfile(*lockfileFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *remoteFlag || *cacheDirFlag != "" || *offlineFlag || *lockfileFlag != "" {
+		g.Loaders = map[string]generate.Loader{"http": loader, "https": loader}
+	}
 
 	err = g.CreateTypes()
 	if err != nil {
@@ -52,16 +129,64 @@ func main() {
 		os.Exit(1)
 	}
 
-	var w io.Writer = os.Stdout
+	if loader.Lockfile != nil && loader.Lockfile.Changed() {
+		if err := loader.Lockfile.Write(*lockfileFlag); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing lockfile: ", err)
+			os.Exit(1)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := generate.Output(buf, g, *p); err != nil {
+		fmt.Fprintln(os.Stderr, "Failure generating code: ", err)
+		os.Exit(1)
+	}
+
+	if *o == "" {
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing output: ", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	if *o != "" {
-		w, err = os.Create(*o)
+	if err := writeFile(*o, buf.Bytes()); err != nil {
+		fmt.Fprintln(os.Stderr, "Error writing output file: ", err)
+		os.Exit(1)
+	}
+}
 
+// writeFile writes data to a temporary file alongside path, then renames it into place, so that the file at path is
+// never left partially written.
+func writeFile(path string, data []byte) (err error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	defer func() {
 		if err != nil {
-			fmt.Fprintln(os.Stderr, "Error opening output file: ", err)
-			return
+			os.Remove(f.Name())
 		}
-	}
+	}()
 
-	generate.Output(w, g, *p)
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	// temporary files are only readable by their owner, so keep the mode of any existing file instead
+	mode := os.FileMode(0644)
+	if fi, statErr := os.Stat(path); statErr == nil {
+		mode = fi.Mode().Perm()
+	}
+	if err = os.Chmod(f.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
 }
@@ -1 +1,58 @@
 package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestThatWriteFileReplacesTheOutputFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema-generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "generated.go")
+	if err := ioutil.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFile(path, []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("expected the file to contain %q, got %q", "new", string(data))
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("expected the file mode to be kept, got %v", fi.Mode().Perm())
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected the temporary file to be renamed, but found %d files", len(files))
+	}
+}
+
+func TestThatWriteFileFailsWhenTheDirectoryDoesNotExist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema-generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeFile(filepath.Join(dir, "missing", "generated.go"), []byte("new")); err == nil {
+		t.Error("expected an error")
+	}
+}
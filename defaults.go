@@ -0,0 +1,322 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultsEmitter writes the code which sets fields to the default values of their schemas.
+type defaultsEmitter struct {
+	g       *Generator
+	imports map[string]bool
+}
+
+func newDefaultsEmitter(g *Generator, imports map[string]bool) *defaultsEmitter {
+	return &defaultsEmitter{
+		g:       g,
+		imports: imports,
+	}
+}
+
+// emitConstructor writes a NewX function for a struct with fields which have default values.
+func (d *defaultsEmitter) emitConstructor(w io.Writer, s Struct) {
+	if s.Constructor == "" {
+		return
+	}
+	fmt.Fprintf(w, `
+// %[2]s returns a %[1]s with the default values of the JSON schema.
+func %[2]s() *%[1]s {
+    strct := &%[1]s{}
+`, s.Name, s.Constructor)
+	for _, fieldKey := range s.fieldNames() {
+		f := s.Fields[fieldKey]
+		// the default values were checked by CreateTypes, so they're expected to unmarshal
+		if value, ok := d.g.getDefault(f.schema); ok && f.JSONName != "-" {
+			d.emitDefault(w, "strct."+f.Name, f.Type, value, "")
+		}
+	}
+	fmt.Fprintf(w, "    return strct\n")
+	fmt.Fprintf(w, "}\n")
+}
+
+// emitMissing writes code for an UnmarshalJSON method which sets the fields missing from jsonMap to their default
+// values. When validate is true, errors are added to errs, otherwise the first is returned.
+func (d *defaultsEmitter) emitMissing(w io.Writer, s Struct, validate bool) {
+	for _, fieldKey := range s.fieldNames() {
+		f := s.Fields[fieldKey]
+		value, ok := d.g.getDefault(f.schema)
+		if !ok || f.JSONName == "-" {
+			continue
+		}
+		fmt.Fprintf(w, "    if _, ok := jsonMap[%q]; !ok {\n", f.JSONName)
+		buf := new(bytes.Buffer)
+		onError := "return err"
+		if validate {
+			onError = fmt.Sprintf("errs.Merge(%q, err)", jsonPointer(f.JSONName))
+		}
+		d.emitDefault(buf, "strct."+f.Name, f.Type, value, onError)
+		w.Write(indent(buf.Bytes()))
+		fmt.Fprintf(w, "    }\n")
+	}
+}
+
+// emitDefault writes code which sets target, a golang expression of type typ, to value. Values which can't be
+// written as golang literals are unmarshalled from JSON, and onError is the statement run if that fails, or empty to
+// ignore the error.
+func (d *defaultsEmitter) emitDefault(w io.Writer, target, typ string, value interface{}, onError string) {
+	if elem, ok := getWrappedType(typ); ok {
+		if literal, ok := d.literal(elem, value); ok {
+			fmt.Fprintf(w, "    %s.Set(%s)\n", target, literal)
+			return
+		}
+	}
+	if elem := strings.TrimPrefix(typ, "*"); elem != typ && d.g.isValueType(elem) {
+		if literal, ok := d.literal(elem, value); ok {
+			fmt.Fprintf(w, "    %[1]s = new(%[2]s)\n    *%[1]s = %[3]s\n", target, elem, literal)
+			return
+		}
+	}
+	if literal, ok := d.literal(typ, value); ok {
+		fmt.Fprintf(w, "    %s = %s\n", target, literal)
+		return
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		fmt.Fprintf(w, "    // the default value can't be encoded as JSON: %v\n", err)
+		return
+	}
+	d.imports["encoding/json"] = true
+	if onError == "" {
+		fmt.Fprintf(w, "    _ = json.Unmarshal([]byte(%s), &%s)\n", strconv.Quote(string(b)), target)
+		return
+	}
+	fmt.Fprintf(w, `    if err := json.Unmarshal([]byte(%s), &%s); err != nil {
+        %s
+    }
+`, strconv.Quote(string(b)), target, onError)
+}
+
+// literal returns the golang literal of the value for the type, e.g. "\"a\"" for a string, or false if the value
+// can't be written as a literal of the type.
+func (d *defaultsEmitter) literal(typ string, value interface{}) (string, bool) {
+	if e, isEnum := d.g.Enums[typ]; isEnum {
+		literal, ok := d.literal(e.Type, value)
+		for _, v := range e.Values {
+			if ok && v.Value == literal {
+				return v.Name, true
+			}
+		}
+		return "", false
+	}
+	if elem := strings.TrimPrefix(typ, "[]"); elem != typ {
+		values, ok := value.([]interface{})
+		if !ok {
+			return "", false
+		}
+		literals := make([]string, len(values))
+		for i, v := range values {
+			if literals[i], ok = d.literal(elem, v); !ok {
+				return "", false
+			}
+		}
+		return typ + "{" + strings.Join(literals, ", ") + "}", true
+	}
+	switch v := value.(type) {
+	case string:
+		if typ == "string" {
+			return strconv.Quote(v), true
+		}
+	case bool:
+		if typ == "bool" {
+			return strconv.FormatBool(v), true
+		}
+	case float64:
+		if typ == "float64" || isIntegerType(typ) && v == math.Trunc(v) {
+			return formatFloat(v), true
+		}
+	}
+	return "", false
+}
+
+// checkDefaults returns an error for the first default value of a struct field which the field's type can't hold,
+// and names the constructors of the structs which have default values.
+func (g *Generator) checkDefaults() error {
+	for _, k := range getOrderedStructNames(g.Structs) {
+		s := g.Structs[k]
+		if g.hasDefaults(s) {
+			constructor, err := g.reserveName("New"+s.Name, g.schemaLocation(s.schema)+"/default", s.schema)
+			if err != nil {
+				return err
+			}
+			s.Constructor = constructor
+			g.Structs[k] = s
+		}
+		for _, fieldKey := range s.fieldNames() {
+			f := s.Fields[fieldKey]
+			value, ok := g.getDefault(f.schema)
+			if !ok || f.JSONName == "-" {
+				continue
+			}
+			if reason := g.checkValue(f.Type, value); reason != "" {
+				return fmt.Errorf("the default value of \"%s\" is invalid: %s", g.schemaPath(f.schema), reason)
+			}
+		}
+	}
+	return nil
+}
+
+// checkValue returns the reason that a value decoded from JSON can't be unmarshalled into the type, or an empty
+// string if it can, or if the type isn't one that the generator knows the JSON representation of, e.g. a mapped type.
+func (g *Generator) checkValue(typ string, value interface{}) string {
+	b, _ := json.Marshal(value)
+	mismatch := fmt.Sprintf("%s can't hold %s", typ, b)
+	if elem, ok := getWrappedType(typ); ok {
+		if value == nil && strings.HasPrefix(typ, "types.Nullable[") {
+			return ""
+		}
+		typ = elem
+	}
+	if value == nil {
+		if isNillable(typ) {
+			return ""
+		}
+		return mismatch
+	}
+	if elem := strings.TrimPrefix(typ, "*"); elem != typ && (g.isValueType(elem) || elem == "big.Int") {
+		typ = elem
+	}
+	if a, isAlias := g.Aliases[typ]; isAlias {
+		return g.checkValue(a.Type, value)
+	}
+	if e, isEnum := g.Enums[typ]; isEnum {
+		// the values of enums of mixed types are held as their JSON encoding
+		if _, ok := (&defaultsEmitter{g: g}).literal(typ, value); ok || e.Type == "" && e.hasValue(strconv.Quote(string(b))) {
+			return ""
+		}
+		return fmt.Sprintf("%s is not one of the values of %s", b, typ)
+	}
+	if u, isUnion := g.Unions[strings.TrimPrefix(typ, "*")]; isUnion {
+		for _, v := range u.Variants {
+			if g.checkValue(v.Type, value) == "" {
+				return ""
+			}
+		}
+		return mismatch
+	}
+	if s, isStruct := g.Structs[strings.TrimPrefix(typ, "*")]; isStruct {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		for _, fieldKey := range s.fieldNames() {
+			f := s.Fields[fieldKey]
+			if v, ok := object[f.JSONName]; ok {
+				if reason := g.checkValue(f.Type, v); reason != "" {
+					return f.JSONName + ": " + reason
+				}
+			}
+		}
+		return ""
+	}
+	if t, isTuple := g.Tuples[strings.TrimPrefix(typ, "*")]; isTuple {
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		for i, f := range t.Items {
+			if i < len(items) {
+				if reason := g.checkValue(f.Type, items[i]); reason != "" {
+					return strconv.Itoa(i) + ": " + reason
+				}
+			}
+		}
+		return ""
+	}
+	switch {
+	case typ == "string" || typ == "[]byte" || isFormatType(typ):
+		if _, ok := value.(string); !ok {
+			return mismatch
+		}
+	case typ == "bool":
+		if _, ok := value.(bool); !ok {
+			return mismatch
+		}
+	case typ == "float64" || typ == "json.Number" || typ == "big.Int":
+		if _, ok := value.(float64); !ok {
+			return mismatch
+		}
+	case isIntegerType(typ):
+		if _, ok := (&defaultsEmitter{g: g}).literal(typ, value); !ok {
+			return mismatch
+		}
+	case strings.HasPrefix(typ, "[]"):
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		for i, item := range items {
+			if reason := g.checkValue(typ[2:], item); reason != "" {
+				return strconv.Itoa(i) + ": " + reason
+			}
+		}
+	case strings.HasPrefix(typ, "map[string]"):
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		for _, k := range getOrderedInterfaceKeys(object) {
+			if reason := g.checkValue(typ[len("map[string]"):], object[k]); reason != "" {
+				return k + ": " + reason
+			}
+		}
+	}
+	return ""
+}
+
+// isFormatType returns true for the types used for strings with a format, which are unmarshalled from JSON strings.
+func isFormatType(typ string) bool {
+	for _, f := range formatTypes {
+		if f.name == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func getOrderedInterfaceKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// getDefault returns the default value of the schema, or of the schema that it references.
+func (g *Generator) getDefault(schema *Schema) (value interface{}, ok bool) {
+	if schema == nil {
+		return nil, false
+	}
+	if schema.Default == nil {
+		if resolved, err := g.resolveReferences(schema); err == nil {
+			schema = resolved
+		}
+	}
+	return schema.Default, schema.Default != nil
+}
+
+// hasDefaults returns true when any of the fields of the struct have default values.
+func (g *Generator) hasDefaults(s Struct) bool {
+	for _, f := range s.Fields {
+		if _, ok := g.getDefault(f.schema); ok && f.JSONName != "-" {
+			return true
+		}
+	}
+	return false
+}
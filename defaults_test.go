@@ -0,0 +1,113 @@
+package generate
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestThatDefaultValuesAreWrittenAsLiterals(t *testing.T) {
+	g := New()
+	g.Enums["Level"] = Enum{Name: "Level", Type: "string", Values: []EnumValue{{Name: "LevelInfo", Value: `"info"`}}}
+	d := newDefaultsEmitter(g, map[string]bool{})
+
+	tests := []struct {
+		typ      string
+		value    interface{}
+		expected string
+		ok       bool
+	}{
+		{typ: "string", value: "a", expected: `"a"`, ok: true},
+		{typ: "int", value: 3.0, expected: "3", ok: true},
+		{typ: "int", value: 3.5, ok: false},
+		{typ: "float64", value: 3.5, expected: "3.5", ok: true},
+		{typ: "bool", value: true, expected: "true", ok: true},
+		{typ: "Level", value: "info", expected: "LevelInfo", ok: true},
+		{typ: "Level", value: "debug", ok: false},
+		{typ: "[]int", value: []interface{}{1.0, 2.0}, expected: "[]int{1, 2}", ok: true},
+		{typ: "string", value: 1.0, ok: false},
+		{typ: "*Address", value: map[string]interface{}{}, ok: false},
+	}
+	for _, test := range tests {
+		actual, ok := d.literal(test.typ, test.value)
+		if ok != test.ok || actual != test.expected {
+			t.Errorf("%s %v: expected %q, %v, got %q, %v", test.typ, test.value, test.expected, test.ok, actual, ok)
+		}
+	}
+}
+
+func TestThatDefaultValuesAreCheckedAgainstTheirTypes(t *testing.T) {
+	g := New()
+	g.Enums["Level"] = Enum{Name: "Level", Type: "string", Values: []EnumValue{{Name: "LevelInfo", Value: `"info"`}}}
+	g.Structs["Address"] = Struct{Name: "Address", Fields: map[string]Field{"Lines": {Name: "Lines", JSONName: "lines", Type: "[]string"}}}
+
+	tests := []struct {
+		typ   string
+		value interface{}
+		ok    bool
+	}{
+		{typ: "string", value: "a", ok: true},
+		{typ: "string", value: 1.0, ok: false},
+		{typ: "*int", value: 3.0, ok: true},
+		{typ: "int", value: 3.5, ok: false},
+		{typ: "types.Optional[bool]", value: "yes", ok: false},
+		{typ: "Level", value: "debug", ok: false},
+		{typ: "time.Time", value: "2018-03-04T05:06:07Z", ok: true},
+		{typ: "time.Time", value: 1.0, ok: false},
+		{typ: "[]int", value: []interface{}{1.0, "2"}, ok: false},
+		{typ: "map[string]float64", value: map[string]interface{}{"a": 1.5}, ok: true},
+		{typ: "*Address", value: map[string]interface{}{"lines": []interface{}{"1 High Street"}}, ok: true},
+		{typ: "*Address", value: map[string]interface{}{"lines": "1 High Street"}, ok: false},
+		{typ: "*Address", value: "1 High Street", ok: false},
+		{typ: "decimal.Decimal", value: "1.5", ok: true},
+	}
+	for _, test := range tests {
+		if reason := g.checkValue(test.typ, test.value); (reason == "") != test.ok {
+			t.Errorf("%s %v: expected ok to be %v, got %q", test.typ, test.value, test.ok, reason)
+		}
+	}
+}
+
+func TestThatInvalidDefaultValuesResultInAnError(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Config",
+		"properties": { "port": { "type": "integer", "default": "http" } } }`, &url.URL{Scheme: "file", Path: "defaults_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = New(root).CreateTypes()
+	if err == nil || !strings.Contains(err.Error(), "#/properties/port") {
+		t.Errorf("expected an error for the default value of the port, got %v", err)
+	}
+}
+
+func TestThatConstructorsDoNotClashWithTypes(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root",
+		"properties": { "config": { "$ref": "#/definitions/Config" }, "other": { "$ref": "#/definitions/newConfig" } },
+		"definitions": {
+			"Config": { "type": "object", "properties": { "port": { "type": "integer", "default": 80 } } },
+			"newConfig": { "type": "object", "properties": { "name": { "type": "string" } } } } }`,
+		&url.URL{Scheme: "file", Path: "defaults_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.Structs["NewConfig"]; !ok {
+		t.Fatalf("expected the NewConfig type, got %v", getOrderedStructNames(g.Structs))
+	}
+	constructor := g.Structs["Config"].Constructor
+	if constructor == "" || constructor == "NewConfig" {
+		t.Errorf("expected the constructor of Config to be renamed, got %q", constructor)
+	}
+
+	var buf bytes.Buffer
+	if err := Output(&buf, g, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "func "+constructor+"() *Config {") {
+		t.Errorf("expected the %s constructor, got:\n%s", constructor, buf.String())
+	}
+}
@@ -1,11 +1,15 @@
 package generate
 
 import (
-	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go/ast"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
 	"strings"
-	"unicode"
 )
 
 // Generator will produce structs from the JSON schema.
@@ -14,54 +18,145 @@ type Generator struct {
 	resolver *RefResolver
 	Structs  map[string]Struct
 	Aliases  map[string]Field
+	Unions   map[string]Union
+	Enums    map[string]Enum
+	Tuples   map[string]Tuple
 	// cache for reference types; k=url v=type
-	refs      map[string]string
-	anonCount int
+	refs map[string]string
+	// packages used by the generated types; k=import path
+	imports map[string]bool
+	// names given to the packages of mapped types which clash with other packages; k=import path v=name
+	importNames map[string]string
+	// packages of mapped types; k=name v=import path
+	mappedPackages map[string]string
+	// names of the generated types; k=name v=location of the schema the type was generated from
+	typeNames map[string]string
+	// the upper case Initialisms
+	initialisms map[string]bool
+
+	// GenerateValidation adds a Validate method to each of the generated types, which checks the values against
+	// the constraints of the JSON schema.
+	GenerateValidation bool
+
+	// FormatTypes maps strings with a "format" to richer golang types, e.g. "date-time" to time.Time, rather than
+	// string.
+	FormatTypes bool
+
+	// TypeMappings override the golang types generated for the schemas which they match.
+	TypeMappings []TypeMapping
+
+	// Initialisms are written in upper case when they appear as words in golang names, e.g. "ID" in "UserID" rather
+	// than "UserId". CommonInitialisms holds the list that golint checks for.
+	Initialisms []string
+
+	// OptionalPolicy sets how the values of optional and nullable properties are represented.
+	OptionalPolicy OptionalPolicy
+
+	// ApplyDefaults makes the generated UnmarshalJSON methods set the properties which are missing from the JSON to
+	// the default values of their schemas. A NewX function which returns a struct holding the default values is
+	// generated whether or not this is set.
+	ApplyDefaults bool
+
+	// InferIntegerTypes uses the narrowest golang integer type which holds every value between the "minimum" and
+	// "maximum" of an integer schema without a "format", e.g. uint8 for a minimum of 0 and a maximum of 255.
+	InferIntegerTypes bool
+
+	// BigIntegers uses *big.Int rather than json.Number for integers whose bounds don't fit in an int64 or uint64.
+	BigIntegers bool
+
+	// Loaders read the documents which are referenced by the schemas, but weren't passed to the generator, keyed by
+	// URI scheme, e.g. an HTTPLoader for "https". Files are read from disk unless a Loader is set for "file".
+	Loaders map[string]Loader
+
+	// Catalog maps the URIs of the documents referenced by the schemas to local files, which are read rather than
+	// using the Loaders.
+	Catalog Catalog
+
+	// PreserveOrder declares struct fields, and writes them in MarshalJSON, in the order the properties appear in
+	// the schema, rather than in alphabetical order.
+	PreserveOrder bool
 }
 
+// OptionalPolicy sets how the generator represents the values of properties of primitive types, e.g. string, which
+// are optional or nullable.
+type OptionalPolicy int
+
+const (
+	// OptionalValues uses values, e.g. string, for optional properties, which are omitted when they hold the zero
+	// value. Nullable properties use pointers.
+	OptionalValues OptionalPolicy = iota
+	// OptionalPointers uses pointers, e.g. *string, for optional and nullable properties, which are omitted, or null,
+	// when they're nil.
+	OptionalPointers
+	// OptionalGeneric uses types.Optional, e.g. types.Optional[string], for optional and nullable properties. The
+	// generated code needs Go 1.18 or later.
+	OptionalGeneric
+	// OptionalTriState uses types.Nullable, e.g. types.Nullable[string], for optional and nullable properties of
+	// any type, so that a missing value, null and a value can be told apart, as needed by JSON Merge Patch. The
+	// generated code needs Go 1.18 or later.
+	OptionalTriState
+)
+
 // New creates an instance of a generator which will produce structs.
 func New(schemas ...*Schema) *Generator {
 	return &Generator{
-		schemas:  schemas,
-		resolver: NewRefResolver(schemas),
-		Structs:  make(map[string]Struct),
-		Aliases:  make(map[string]Field),
-		refs:     make(map[string]string),
+		schemas:   schemas,
+		resolver:  NewRefResolver(schemas),
+		Structs:   make(map[string]Struct),
+		Aliases:   make(map[string]Field),
+		Unions:    make(map[string]Union),
+		Enums:     make(map[string]Enum),
+		Tuples:    make(map[string]Tuple),
+		refs:      make(map[string]string),
+		imports:   make(map[string]bool),
+		typeNames: make(map[string]string),
+
+		importNames:    make(map[string]string),
+		mappedPackages: make(map[string]string),
 	}
 }
 
 // CreateTypes creates types from the JSON schemas, keyed by the golang name.
 func (g *Generator) CreateTypes() (err error) {
+	for scheme, loader := range g.Loaders {
+		g.resolver.Loaders[scheme] = loader
+	}
+	g.resolver.Catalog = g.Catalog
 	if err := g.resolver.Init(); err != nil {
 		return err
 	}
 
 	// extract the types
 	for _, schema := range g.schemas {
-		name := g.getSchemaName("", schema)
+		name, err := g.reserveTypeName(g.getSchemaName("", schema), schema)
+		if err != nil {
+			return err
+		}
 		rootType, err := g.processSchema(name, schema)
 		if err != nil {
 			return err
 		}
 		// ugh: if it was anything but a struct the type will not be the name...
-		if rootType != "*"+name {
+		if rootType != "*"+name && rootType != name {
 			a := Field{
 				Name:        name,
 				JSONName:    "",
 				Type:        rootType,
 				Required:    false,
 				Description: schema.Description,
+				schema:      schema,
 			}
 			g.Aliases[a.Name] = a
 		}
 	}
-	return
+	return g.checkDefaults()
 }
 
 // process a block of definitions
 func (g *Generator) processDefinitions(schema *Schema) error {
-	for key, subSchema := range schema.Definitions {
-		if _, err := g.processSchema(getGolangName(key), subSchema); err != nil {
+	definitions := schema.definitionSchemas()
+	for _, key := range schema.definitionKeys() {
+		if _, err := g.processSchema(g.getGolangName(key), definitions[key]); err != nil {
 			return err
 		}
 	}
@@ -76,7 +171,7 @@ func (g *Generator) processReference(schema *Schema) (string, error) {
 	}
 	refSchema, err := g.resolver.GetSchemaByReference(schema)
 	if err != nil {
-		return "", errors.New("processReference: reference \"" + schema.Reference + "\" not found at \"" + schemaPath + "\"")
+		return "", g.referenceError(schema, err)
 	}
 	if refSchema.GeneratedType == "" {
 		// reference is not resolved yet. Do that now.
@@ -90,15 +185,45 @@ func (g *Generator) processReference(schema *Schema) (string, error) {
 	return refSchema.GeneratedType, nil
 }
 
+// referenceError returns the error for a reference which couldn't be resolved.
+func (g *Generator) referenceError(schema *Schema, err error) error {
+	switch err.(type) {
+	case *loadError, *pointerError:
+		return fmt.Errorf("processReference: reference \"%s\" at \"%s\" can't be resolved: %v", schema.Reference, g.resolver.GetPath(schema), err)
+	}
+	return errors.New("processReference: reference \"" + schema.Reference + "\" not found at \"" + g.resolver.GetPath(schema) + "\"")
+}
+
 // returns the type refered to by schema after resolving all dependencies
 func (g *Generator) processSchema(schemaName string, schema *Schema) (typ string, err error) {
-	if len(schema.Definitions) > 0 {
-		g.processDefinitions(schema)
+	if typ, ok, err := g.getMappedTypeName(schema); ok || err != nil {
+		return typ, err
+	}
+	if len(schema.definitionSchemas()) > 0 {
+		if err := g.processDefinitions(schema); err != nil {
+			return "", err
+		}
+	}
+	if len(schema.AllOf) > 0 || schema.refHasSiblings() {
+		return g.processAllOf(schemaName, schema)
+	}
+	if (len(schema.OneOf) > 0 || len(schema.AnyOf) > 0) && len(schema.Properties) == 0 {
+		typ, err := g.processUnion(schemaName, schema)
+		if err != nil || typ != "" {
+			return typ, err
+		}
+	}
+	if len(schema.Enum) > 0 {
+		return g.processEnum(schemaName, schema)
 	}
 	schema.FixMissingTypeValue()
 	// if we have multiple schema types, the golang type will be interface{}
 	typ = "interface{}"
 	types, isMultiType := schema.MultiType()
+	// a nullable type is represented by a nillable golang type, see getOptionalTypeName
+	if isMultiType && contains(types, "null") && len(types) == 2 {
+		types, isMultiType = withoutNull(types), false
+	}
 	if len(types) > 0 {
 		for _, schemaType := range types {
 			name := schemaName
@@ -127,6 +252,12 @@ func (g *Generator) processSchema(schemaName string, schema *Schema) (typ string
 				if err != nil {
 					return "", err
 				}
+				if schemaType == "string" && g.FormatTypes {
+					rv = g.getFormatTypeName(schema, rv)
+				}
+				if schemaType == "integer" {
+					rv = g.getIntegerTypeName(schema)
+				}
 				if !isMultiType {
 					return rv, nil
 				}
@@ -140,9 +271,489 @@ func (g *Generator) processSchema(schemaName string, schema *Schema) (typ string
 	return // return interface{}
 }
 
+// name: name of the merged type
+// schema: schema containing the allOf sub-schemas
+// returns: the type generated for the merged sub-schemas
+func (g *Generator) processAllOf(name string, schema *Schema) (typ string, err error) {
+	// an allOf containing a single reference is often used to annotate a $ref, so re-use the referenced type
+	if len(schema.AllOf) == 1 && schema.AllOf[0].Reference != "" && len(schema.Properties) == 0 {
+		return g.processReference(schema.AllOf[0])
+	}
+	if name, err = g.reserveTypeName(name, schema); err != nil {
+		return "", err
+	}
+	merged := &Schema{
+		ID04:        schema.ID04,
+		ID06:        schema.ID06,
+		Title:       schema.Title,
+		Description: schema.Description,
+		Properties:  make(map[string]*Schema),
+		Parent:      schema.Parent,
+		JSONKey:     schema.JSONKey,
+		PathElement: schema.PathElement,
+	}
+	conflicts := []propertyConflict{}
+	if err := g.mergeSchema(merged, schema, &conflicts, map[*Schema]bool{}); err != nil {
+		return "", err
+	}
+	merged.FixMissingTypeValue()
+	// cache the object name in case any sub-schemas recursively reference it, but not once it turns out to be invalid,
+	// so that other references to the schema don't re-use it
+	if t, _ := merged.Type(); t == "object" {
+		schema.GeneratedType = "*" + name
+		defer func() {
+			if err != nil {
+				schema.GeneratedType = ""
+			}
+		}()
+	}
+	typ, err = g.processSchema(name, merged)
+	if err != nil {
+		return "", err
+	}
+	// the same property may be declared by more than one sub-schema, as long as the types agree
+	for _, c := range conflicts {
+		fieldName := g.getGolangName(c.key)
+		// the first declaration is processed last, so that it's the one left in g.Structs
+		other, err := g.processSchema(g.getSchemaName(fieldName, c.other), c.other)
+		if err != nil {
+			return "", err
+		}
+		first, err := g.processSchema(g.getSchemaName(fieldName, c.first), c.first)
+		if err != nil {
+			return "", err
+		}
+		if first != other {
+			return "", fmt.Errorf("processAllOf: property \"%s\" has conflicting types %s at \"%s\" and %s at \"%s\"",
+				c.key, first, g.resolver.GetPath(c.first), other, g.resolver.GetPath(c.other))
+		}
+		// the constraints of the other declaration apply as well as those of the first
+		if strct, ok := g.Structs[strings.TrimPrefix(typ, "*")]; ok {
+			for k, f := range strct.Fields {
+				if f.JSONName == c.key && f.schema == c.first {
+					f.allOf = append(f.allOf, c.other)
+					strct.Fields[k] = f
+				}
+			}
+		}
+	}
+	return typ, nil
+}
+
+// propertyConflict records a property that is declared by more than one sub-schema of an allOf.
+type propertyConflict struct {
+	key   string
+	first *Schema
+	other *Schema
+}
+
+// mergeSchema adds the type, properties and required fields of src to dst, following references and nested allOf
+// sub-schemas. A schema which is already being merged, e.g. because an allOf references the schema containing it,
+// has nothing more to add, so it's skipped.
+func (g *Generator) mergeSchema(dst *Schema, src *Schema, conflicts *[]propertyConflict, merging map[*Schema]bool) error {
+	if merging[src] {
+		return nil
+	}
+	merging[src] = true
+	defer delete(merging, src)
+	var refSchema *Schema
+	if src.Reference != "" {
+		var err error
+		refSchema, err = g.resolver.GetSchemaByReference(src)
+		if err != nil {
+			return errors.New("processAllOf: reference \"" + src.Reference + "\" not found at \"" + g.resolver.GetPath(src) + "\"")
+		}
+		if !src.refHasSiblings() {
+			return g.mergeSchema(dst, refSchema, conflicts, merging)
+		}
+	}
+	if dst.TypeValue == nil {
+		dst.TypeValue = src.TypeValue
+	}
+	if dst.Description == "" {
+		dst.Description = src.Description
+	}
+	for _, propKey := range src.propertyKeys() {
+		prop := src.Properties[propKey]
+		if existing, ok := dst.Properties[propKey]; ok {
+			if existing != prop {
+				*conflicts = append(*conflicts, propertyConflict{key: propKey, first: existing, other: prop})
+			}
+			continue
+		}
+		dst.Properties[propKey] = prop
+		dst.propertyOrder = append(dst.propertyOrder, propKey)
+	}
+	for _, r := range src.Required {
+		if !contains(dst.Required, r) {
+			dst.Required = append(dst.Required, r)
+		}
+	}
+	if dst.AdditionalProperties == nil {
+		dst.AdditionalProperties = src.AdditionalProperties
+	}
+	if dst.UnevaluatedProperties == nil {
+		dst.UnevaluatedProperties = src.UnevaluatedProperties
+	}
+	if dst.Items == nil && dst.PrefixItems == nil {
+		dst.Items, dst.ItemsBool, dst.PrefixItems = src.Items, src.ItemsBool, src.PrefixItems
+	}
+	if dst.Enum == nil {
+		dst.Enum = src.Enum
+	}
+	// keywords alongside a $ref apply in addition to the referenced schema
+	if refSchema != nil {
+		if err := g.mergeSchema(dst, refSchema, conflicts, merging); err != nil {
+			return err
+		}
+	}
+	for _, subSchema := range src.AllOf {
+		if err := g.mergeSchema(dst, subSchema, conflicts, merging); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// name: name of the union
+// schema: schema containing the oneOf or anyOf sub-schemas
+// returns: the generated union type, or an empty string if the sub-schemas don't each describe a type, in which case
+// the schema is processed as if the oneOf or anyOf wasn't present
+func (g *Generator) processUnion(name string, schema *Schema) (typ string, err error) {
+	subSchemas := schema.OneOf
+	if len(subSchemas) == 0 {
+		subSchemas = schema.AnyOf
+	}
+	if name, err = g.reserveTypeName(name, schema); err != nil {
+		return "", err
+	}
+	// cache the union name in case any sub-schemas recursively reference it
+	schema.GeneratedType = "*" + name
+	union := Union{
+		Name:        name,
+		Description: schema.Description,
+		schema:      schema,
+	}
+	nonNull := []*Schema{}
+	// the aliases are only added once the union is kept, so that none are left over when it isn't
+	aliases := []Field{}
+	for i, subSchema := range subSchemas {
+		// null is represented by a nil Value
+		if t, multiple := subSchema.Type(); t == "null" && !multiple {
+			continue
+		}
+		subName := g.getSchemaName(fmt.Sprintf("%sOption%d", name, i+1), subSchema)
+		subTyp, err := g.processSchema(subName, subSchema)
+		if err != nil {
+			return "", err
+		}
+		if subTyp == "interface{}" {
+			g.releaseTypeNames(aliases)
+			schema.GeneratedType = ""
+			return "", nil
+		}
+		// a type listed more than once, e.g. the same $ref, is only a single variant
+		if union.hasVariant(subTyp) {
+			continue
+		}
+		// only named types can implement the union's interface, so other types are given a name
+		if _, isEnum := g.Enums[subTyp]; !isEnum && !g.isStructPointer(subTyp) {
+			aliasName := subName
+			if isBuiltinType(subTyp) && subSchema.Title == "" {
+				aliasName = name + getGolangName(subTyp)
+			}
+			if union.hasVariant(aliasName) {
+				aliasName = subName
+			}
+			if aliasName, err = g.reserveTypeName(aliasName, subSchema); err != nil {
+				return "", err
+			}
+			aliases = append(aliases, Field{
+				Name:        aliasName,
+				Type:        subTyp,
+				Description: subSchema.Description,
+				schema:      subSchema,
+			})
+			subTyp = aliasName
+		}
+		union.Variants = append(union.Variants, Variant{Type: subTyp})
+		nonNull = append(nonNull, subSchema)
+	}
+	switch len(union.Variants) {
+	case 0:
+		// the only option was null, so the type is whatever the rest of the schema describes
+		schema.GeneratedType = ""
+		return "", nil
+	case 1:
+		// the only other option was null, so there's no need for a union
+		g.releaseTypeNames(aliases)
+		schema.GeneratedType = ""
+		if len(aliases) == 1 {
+			return aliases[0].Type, nil
+		}
+		return union.Variants[0].Type, nil
+	}
+	if err := g.setDiscriminator(&union, schema, nonNull); err != nil {
+		return "", err
+	}
+	// the interface is identified by the keyword holding the variants, e.g. "#/properties/pet/oneOf"
+	keyword := "oneOf"
+	if len(schema.OneOf) == 0 {
+		keyword = "anyOf"
+	}
+	if union.Interface, err = g.reserveName(name+"Value", g.schemaLocation(schema)+"/"+keyword, schema); err != nil {
+		return "", err
+	}
+	for _, alias := range aliases {
+		g.Aliases[alias.Name] = alias
+	}
+	g.Unions[union.Name] = union
+	return "*" + name, nil
+}
+
+// setDiscriminator finds the property that identifies which of the sub-schemas an instance matches, either from an
+// OpenAPI discriminator or from a property with a string const value that all the sub-schemas share.
+func (g *Generator) setDiscriminator(union *Union, schema *Schema, subSchemas []*Schema) error {
+	targets := make([]*Schema, len(subSchemas))
+	for i, subSchema := range subSchemas {
+		target, err := g.resolveReferences(subSchema)
+		if err != nil {
+			return err
+		}
+		targets[i] = target
+	}
+	if schema.Discriminator != nil {
+		union.Discriminator = schema.Discriminator.PropertyName
+		for i, target := range targets {
+			value, err := g.discriminatorValue(schema, target)
+			if err != nil {
+				return err
+			}
+			if value == "" {
+				return errors.New("processUnion: no discriminator value for \"" + g.resolver.GetPath(subSchemas[i]) + "\"")
+			}
+			union.Variants[i].DiscriminatorValue = value
+		}
+		return nil
+	}
+	// look for a property with a const value in every sub-schema
+	candidates := []string{}
+	for k, p := range g.findProperties(targets[0]) {
+		if _, ok := constString(p); ok {
+			candidates = append(candidates, k)
+		}
+	}
+	sort.Strings(candidates)
+	for _, candidate := range candidates {
+		values := make([]string, len(targets))
+		for i, target := range targets {
+			if p, ok := g.findProperties(target)[candidate]; ok {
+				values[i], _ = constString(p)
+			}
+			if values[i] == "" || contains(values[:i], values[i]) {
+				values = nil
+				break
+			}
+		}
+		if values != nil {
+			union.Discriminator = candidate
+			for i, v := range values {
+				union.Variants[i].DiscriminatorValue = v
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// discriminatorValue returns the value of the schema's discriminator property which selects the target schema.
+func (g *Generator) discriminatorValue(schema *Schema, target *Schema) (string, error) {
+	for _, value := range getOrderedStringKeys(schema.Discriminator.Mapping) {
+		ref := schema.Discriminator.Mapping[value]
+		// OpenAPI allows the mapping to use a schema name instead of a reference
+		if !strings.ContainsAny(ref, "#/") {
+			if target.JSONKey == ref {
+				return value, nil
+			}
+			continue
+		}
+		mapped, err := g.resolver.GetSchemaByReference(&Schema{Reference: ref, Parent: schema})
+		if err != nil {
+			return "", errors.New("processUnion: discriminator mapping \"" + ref + "\" not found at \"" + g.resolver.GetPath(schema) + "\"")
+		}
+		if mapped == target {
+			return value, nil
+		}
+	}
+	if p, ok := g.findProperties(target)[schema.Discriminator.PropertyName]; ok {
+		if c, ok := constString(p); ok {
+			return c, nil
+		}
+	}
+	return target.JSONKey, nil
+}
+
+// constString returns the value of a schema which only permits a single string, either through const or a single
+// valued enum.
+func constString(schema *Schema) (string, bool) {
+	if c, ok := schema.Const.(string); ok {
+		return c, true
+	}
+	if len(schema.Enum) == 1 {
+		c, ok := schema.Enum[0].(string)
+		return c, ok
+	}
+	return "", false
+}
+
+// resolveReferences follows references until it finds a schema that isn't one.
+func (g *Generator) resolveReferences(schema *Schema) (*Schema, error) {
+	for schema.Reference != "" {
+		refSchema, err := g.resolver.GetSchemaByReference(schema)
+		if err != nil {
+			return nil, g.referenceError(schema, err)
+		}
+		schema = refSchema
+	}
+	return schema, nil
+}
+
+// findProperties returns the properties of the schema, including those declared by its allOf sub-schemas.
+func (g *Generator) findProperties(schema *Schema) map[string]*Schema {
+	properties := make(map[string]*Schema, len(schema.Properties))
+	for _, subSchema := range schema.AllOf {
+		if resolved, err := g.resolveReferences(subSchema); err == nil {
+			for k, p := range g.findProperties(resolved) {
+				properties[k] = p
+			}
+		}
+	}
+	for k, p := range schema.Properties {
+		properties[k] = p
+	}
+	return properties
+}
+
+// name: name of the enum type
+// schema: schema containing the permitted values
+// returns: the generated enum type
+func (g *Generator) processEnum(name string, schema *Schema) (typ string, err error) {
+	name, err = g.reserveTypeName(name, schema)
+	if err != nil {
+		return "", err
+	}
+	e := Enum{
+		Name:        name,
+		Description: schema.Description,
+		Type:        getEnumType(schema),
+		schema:      schema,
+	}
+	skipNull := hasNullEnumValue(schema)
+	for i, v := range schema.Enum {
+		if v == nil && skipNull {
+			continue
+		}
+		var literal, label string
+		switch e.Type {
+		case "string":
+			literal, label = strconv.Quote(v.(string)), v.(string)
+			if label == "" {
+				label = "Empty"
+			}
+		case "bool":
+			literal = strconv.FormatBool(v.(bool))
+			label = literal
+		case "int", "float64":
+			literal = strconv.FormatFloat(v.(float64), 'f', -1, 64)
+			label = strings.Replace(literal, ".", "Point", 1)
+			if strings.HasPrefix(label, "-") {
+				label = "Minus" + label[1:]
+			}
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return "", fmt.Errorf("processEnum: cannot encode value %v at \"%s\": %v", v, g.resolver.GetPath(schema), err)
+			}
+			literal = strconv.Quote(string(b))
+			switch v.(type) {
+			case string, bool, float64:
+				label = fmt.Sprint(v)
+			case nil:
+				label = "Null"
+			}
+		}
+		valueName := name + strings.TrimPrefix(g.getGolangName(label), "_")
+		if valueName == name || e.hasValueName(valueName) {
+			valueName = fmt.Sprintf("%sValue%d", name, i+1)
+		}
+		// the constant is identified by the location of its value, e.g. "#/properties/colour/enum/0"
+		if valueName, err = g.reserveName(valueName, fmt.Sprintf("%s/enum/%d", g.schemaLocation(schema), i), schema); err != nil {
+			return "", err
+		}
+		e.Values = append(e.Values, EnumValue{Name: valueName, Value: literal})
+	}
+	g.Enums[e.Name] = e
+	return e.Name, nil
+}
+
+// getEnumType returns the golang type shared by all of the enum values, or an empty string if the values are of
+// different JSON types.
+func getEnumType(schema *Schema) string {
+	typ := ""
+	skipNull := hasNullEnumValue(schema)
+	for _, v := range schema.Enum {
+		var t string
+		switch n := v.(type) {
+		case nil:
+			if !skipNull {
+				return ""
+			}
+			continue
+		case string:
+			t = "string"
+		case bool:
+			t = "bool"
+		case float64:
+			t = "float64"
+			if schemaType, _ := schema.Type(); schemaType != "number" && n == math.Trunc(n) {
+				t = "int"
+			}
+		default:
+			return ""
+		}
+		if typ == "int" && t == "float64" || typ == "float64" && t == "int" {
+			t = "float64"
+		} else if typ != "" && typ != t {
+			return ""
+		}
+		typ = t
+	}
+	return typ
+}
+
+// hasNullEnumValue returns true when the enum lists null alongside other values and the schema's type allows null.
+// The null is then represented by a nil pointer to the enum, rather than by one of the enum's values.
+func hasNullEnumValue(schema *Schema) bool {
+	if types, multiple := schema.MultiType(); !multiple || !contains(types, "null") {
+		return false
+	}
+	hasNull, hasOther := false, false
+	for _, v := range schema.Enum {
+		if v == nil {
+			hasNull = true
+		} else {
+			hasOther = true
+		}
+	}
+	return hasNull && hasOther
+}
+
 // name: name of this array, usually the js key
 // schema: items element
 func (g *Generator) processArray(name string, schema *Schema) (typeStr string, err error) {
+	if len(schema.PrefixItems) > 0 {
+		return g.processTuple(name, schema)
+	}
 	if schema.Items != nil {
 		// subType: fallback name in case this array contains inline object without a title
 		subName := g.getSchemaName(name+"Items", schema.Items)
@@ -156,12 +767,16 @@ func (g *Generator) processArray(name string, schema *Schema) (typeStr string, e
 		}
 		// only alias root arrays
 		if schema.Parent == nil {
+			if name, err = g.reserveTypeName(name, schema); err != nil {
+				return "", err
+			}
 			array := Field{
 				Name:        name,
 				JSONName:    "",
 				Type:        finalType,
 				Required:    contains(schema.Required, name),
 				Description: schema.Description,
+				schema:      schema,
 			}
 			g.Aliases[array.Name] = array
 		}
@@ -170,42 +785,105 @@ func (g *Generator) processArray(name string, schema *Schema) (typeStr string, e
 	return "[]interface{}", nil
 }
 
+// name: name of the tuple (calculated by caller)
+// schema: array schema with prefixItems
+// returns: generated type
+func (g *Generator) processTuple(name string, schema *Schema) (typ string, err error) {
+	if name, err = g.reserveTypeName(name, schema); err != nil {
+		return "", err
+	}
+	tuple := Tuple{
+		Name:        name,
+		Description: schema.Description,
+		schema:      schema,
+	}
+	// cache the tuple name in case any sub-schemas recursively reference it
+	schema.GeneratedType = "*" + name
+	for i, item := range schema.PrefixItems {
+		fieldName := fmt.Sprintf("Item%d", i+1)
+		itemType, err := g.processSchema(g.getSchemaName(name+fieldName, item), item)
+		if err != nil {
+			return "", err
+		}
+		tuple.Items = append(tuple.Items, Field{
+			Name:        fieldName,
+			JSONName:    strconv.Itoa(i),
+			Type:        itemType,
+			Required:    schema.MinItems != nil && i < *schema.MinItems,
+			Description: item.Description,
+			schema:      item,
+		})
+	}
+	switch {
+	case schema.ItemsBool != nil:
+		// no more items are permitted
+	case schema.Items != nil:
+		tuple.RestType, err = g.processSchema(g.getSchemaName(name+"Rest", schema.Items), schema.Items)
+		if err != nil {
+			return "", err
+		}
+	default:
+		tuple.RestType = "interface{}"
+	}
+	g.Tuples[tuple.Name] = tuple
+	return getPrimitiveTypeName("object", name, true)
+}
+
 // name: name of the struct (calculated by caller)
 // schema: detail incl properties & child objects
 // returns: generated type
 func (g *Generator) processObject(name string, schema *Schema) (typ string, err error) {
+	if name, err = g.reserveTypeName(name, schema); err != nil {
+		return "", err
+	}
 	strct := Struct{
 		ID:          schema.ID(),
 		Name:        name,
 		Description: schema.Description,
 		Fields:      make(map[string]Field, len(schema.Properties)),
+		schema:      schema,
 	}
 	// cache the object name in case any sub-schemas recursively reference it
 	schema.GeneratedType = "*" + name
+	// unevaluatedProperties are handled in the same way as additionalProperties, since the properties of any allOf
+	// sub-schemas have been merged into this one
+	additionalProperties := schema.AdditionalProperties
+	if additionalProperties == nil {
+		additionalProperties = schema.UnevaluatedProperties
+	}
 	// regular properties
-	for propKey, prop := range schema.Properties {
-		fieldName := getGolangName(propKey)
-		// calculate sub-schema name here, may not actually be used depending on type of schema!
-		subSchemaName := g.getSchemaName(fieldName, prop)
-		fieldType, err := g.processSchema(subSchemaName, prop)
-		if err != nil {
-			return "", err
-		}
-		f := Field{
-			Name:        fieldName,
-			JSONName:    propKey,
-			Type:        fieldType,
-			Required:    contains(schema.Required, propKey),
-			Description: prop.Description,
+	properties := []objectProperty{}
+	for _, propKey := range schema.propertyKeys() {
+		properties = append(properties, objectProperty{propKey, schema.Properties[propKey], contains(schema.Required, propKey)})
+	}
+	// properties which may only be present alongside another are optional
+	for _, dependent := range schema.dependentSchemaList() {
+		for _, propKey := range dependent.propertyKeys() {
+			if !hasProperty(properties, propKey) {
+				properties = append(properties, objectProperty{propKey, dependent.Properties[propKey], false})
+			}
 		}
-		if f.Required {
-			strct.GenerateCode = true
+	}
+	// the AdditionalProperties field holds any properties which aren't declared
+	reserved := []string{"MarshalJSON", "UnmarshalJSON"}
+	if g.GenerateValidation {
+		reserved = append(reserved, "Validate")
+	}
+	if additionalProperties != nil && (additionalProperties.AdditionalPropertiesBool == nil || *additionalProperties.AdditionalPropertiesBool) {
+		reserved = append(reserved, "AdditionalProperties")
+	}
+	fieldNames, err := g.getFieldNames(properties, reserved)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range properties {
+		if err := g.processProperty(&strct, fieldNames[p.key], p.key, p.schema, p.required); err != nil {
+			return "", err
 		}
-		strct.Fields[f.Name] = f
 	}
 	// additionalProperties with typed sub-schema
-	if schema.AdditionalProperties != nil && schema.AdditionalProperties.AdditionalPropertiesBool == nil {
-		ap := (*Schema)(schema.AdditionalProperties)
+	if additionalProperties != nil && additionalProperties.AdditionalPropertiesBool == nil {
+		ap := (*Schema)(additionalProperties)
 		apName := g.getSchemaName("", ap)
 		subTyp, err := g.processSchema(apName, ap)
 		if err != nil {
@@ -217,7 +895,7 @@ func (g *Generator) processObject(name string, schema *Schema) (typ string, err
 		//
 		// If this object is a definition and only contains additional properties, we can't do that or we end up with
 		// no struct
-		isDefinitionObject := strings.HasPrefix(schema.PathElement, "definitions")
+		isDefinitionObject := strings.HasPrefix(schema.PathElement, "definitions/") || strings.HasPrefix(schema.PathElement, "$defs/")
 		if len(schema.Properties) == 0 && !isDefinitionObject {
 			// since there are no regular properties, we don't need to emit a struct for this object - return the
 			// additionalProperties map type.
@@ -231,14 +909,14 @@ func (g *Generator) processObject(name string, schema *Schema) (typ string, err
 			Required:    false,
 			Description: "",
 		}
-		strct.Fields[f.Name] = f
+		strct.addField(f, g.PreserveOrder)
 		// setting this will cause marshal code to be emitted in Output()
 		strct.GenerateCode = true
 		strct.AdditionalType = subTyp
 	}
 	// additionalProperties as either true (everything) or false (nothing)
-	if schema.AdditionalProperties != nil && schema.AdditionalProperties.AdditionalPropertiesBool != nil {
-		if *schema.AdditionalProperties.AdditionalPropertiesBool == true {
+	if additionalProperties != nil && additionalProperties.AdditionalPropertiesBool != nil {
+		if *additionalProperties.AdditionalPropertiesBool == true {
 			// everything is valid additional
 			subTyp := "map[string]interface{}"
 			f := Field{
@@ -248,7 +926,7 @@ func (g *Generator) processObject(name string, schema *Schema) (typ string, err
 				Required:    false,
 				Description: "",
 			}
-			strct.Fields[f.Name] = f
+			strct.addField(f, g.PreserveOrder)
 			// setting this will cause marshal code to be emitted in Output()
 			strct.GenerateCode = true
 			strct.AdditionalType = "interface{}"
@@ -263,6 +941,190 @@ func (g *Generator) processObject(name string, schema *Schema) (typ string, err
 	return getPrimitiveTypeName("object", name, true)
 }
 
+// processProperty adds a field for the property to the struct.
+func (g *Generator) processProperty(strct *Struct, fieldName, propKey string, prop *Schema, required bool) error {
+	// calculate sub-schema name here, may not actually be used depending on type of schema!
+	subSchemaName := g.getSchemaName(fieldName, prop)
+	fieldType, err := g.processSchema(subSchemaName, prop)
+	if err != nil {
+		return err
+	}
+	f := Field{
+		Name:        fieldName,
+		JSONName:    propKey,
+		Type:        g.getOptionalTypeName(fieldType, prop, required),
+		Required:    required,
+		Description: prop.Description,
+		schema:      prop,
+	}
+	// unset optional values are omitted by the generated MarshalJSON, since omitempty doesn't apply to structs
+	if f.Required || isOptionalType(f.Type) {
+		strct.GenerateCode = true
+	}
+	if _, hasDefault := g.getDefault(prop); hasDefault && g.ApplyDefaults {
+		strct.GenerateCode = true
+	}
+	strct.addField(f, g.PreserveOrder)
+	return nil
+}
+
+// getOptionalTypeName returns the type of a property's value, which is nillable when the property is nullable, or when
+// it's optional and the OptionalPolicy says so, so that the zero value can be told apart from a missing one.
+func (g *Generator) getOptionalTypeName(typ string, schema *Schema, required bool) string {
+	nullable := schema.isNullable()
+	if required && !nullable {
+		return typ
+	}
+	if g.OptionalPolicy == OptionalTriState {
+		g.imports[typesImport] = true
+		return "types.Nullable[" + typ + "]"
+	}
+	if !g.isValueType(typ) {
+		return typ
+	}
+	switch {
+	case g.OptionalPolicy == OptionalGeneric:
+		g.imports[typesImport] = true
+		return "types.Optional[" + typ + "]"
+	case g.OptionalPolicy == OptionalPointers || nullable:
+		return "*" + typ
+	}
+	return typ
+}
+
+// isValueType returns true for the types which can't represent a missing value, e.g. string and enums.
+func (g *Generator) isValueType(typ string) bool {
+	if _, isEnum := g.Enums[typ]; isEnum || typ == "json.Number" {
+		return true
+	}
+	for _, f := range formatTypes {
+		if f.name == typ {
+			return !isNillable(typ)
+		}
+	}
+	return isBuiltinType(typ)
+}
+
+// isOptionalType returns true when the type is a types.Optional or types.Nullable.
+func isOptionalType(typ string) bool {
+	_, ok := getWrappedType(typ)
+	return ok
+}
+
+// getWrappedType returns the type of the value held by a types.Optional or types.Nullable, e.g. "string" for
+// "types.Optional[string]".
+func getWrappedType(typ string) (elem string, ok bool) {
+	for _, prefix := range []string{"types.Optional[", "types.Nullable["} {
+		if strings.HasPrefix(typ, prefix) {
+			return strings.TrimSuffix(typ[len(prefix):], "]"), true
+		}
+	}
+	return "", false
+}
+
+func withoutNull(types []string) []string {
+	rv := []string{}
+	for _, t := range types {
+		if t != "null" {
+			rv = append(rv, t)
+		}
+	}
+	return rv
+}
+
+// objectProperty is a property of an object schema.
+type objectProperty struct {
+	key      string
+	schema   *Schema
+	required bool
+}
+
+func hasProperty(properties []objectProperty, key string) bool {
+	for _, p := range properties {
+		if p.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// getFieldNames returns the golang field names of the properties, keyed by the property key. A property's
+// "x-go-name" keyword sets its name, otherwise the key is converted to a golang name. When keys convert to the same
+// name, e.g. "foo-bar" and "foo_bar", the later properties are given a numeric suffix, e.g. "FooBar2".
+func (g *Generator) getFieldNames(properties []objectProperty, reserved []string) (map[string]string, error) {
+	names := make(map[string]string, len(properties))
+	used := make(map[string]string, len(properties)+len(reserved))
+	for _, name := range reserved {
+		used[name] = ""
+	}
+	// the explicit names are allocated first, so that they don't depend on the order of the properties
+	for _, p := range properties {
+		v, ok := p.schema.Extensions["x-go-name"]
+		if !ok {
+			continue
+		}
+		name, isString := v.(string)
+		if !isString || name == "" || name != getGolangName(name) {
+			return nil, fmt.Errorf("x-go-name must be an exported golang identifier at \"%s\"", g.resolver.GetPath(p.schema))
+		}
+		if other, clash := used[name]; clash && other == "" {
+			return nil, fmt.Errorf("x-go-name %s is reserved at \"%s\"", name, g.resolver.GetPath(p.schema))
+		} else if clash {
+			return nil, fmt.Errorf("x-go-name %s of property \"%s\" is already used by \"%s\" at \"%s\"",
+				name, p.key, other, g.resolver.GetPath(p.schema))
+		}
+		names[p.key] = name
+		used[name] = p.key
+	}
+	for _, p := range properties {
+		if _, ok := names[p.key]; ok {
+			continue
+		}
+		base := g.getGolangName(p.key)
+		// unexported fields aren't marshalled, e.g. "_1" for the key "1"
+		if !ast.IsExported(base) {
+			base = "X" + base
+		}
+		name := base
+		for i := 2; ; i++ {
+			if _, clash := used[name]; !clash {
+				break
+			}
+			name = base + strconv.Itoa(i)
+		}
+		names[p.key] = name
+		used[name] = p.key
+	}
+	return names, nil
+}
+
+func getOrderedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func getOrderedStringSliceKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func getOrderedSchemaKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func contains(s []string, e string) bool {
 	for _, a := range s {
 		if a == e {
@@ -272,12 +1134,43 @@ func contains(s []string, e string) bool {
 	return false
 }
 
+// isStructPointer returns true when typ is a pointer to a generated struct or union, e.g. "*Address". The struct
+// may still be in the process of being generated.
+func (g *Generator) isStructPointer(typ string) bool {
+	if !strings.HasPrefix(typ, "*") || isBuiltinType(typ[1:]) {
+		return false
+	}
+	return strings.IndexFunc(typ[1:], func(r rune) bool { return r != '_' && isNotAGoNameCharacter(r) }) == -1
+}
+
+// isBuiltinType returns true when the type is a predeclared Go type, such as "string" or "float64".
+func isBuiltinType(typ string) bool {
+	switch typ {
+	case "bool", "float64", "string":
+		return true
+	}
+	return isIntegerType(typ)
+}
+
+// isIntegerType returns true when the type is a predeclared golang integer type, such as "int" or "uint8".
+func isIntegerType(typ string) bool {
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
 func getPrimitiveTypeName(schemaType string, subType string, pointer bool) (name string, err error) {
 	switch schemaType {
 	case "array":
 		if subType == "" {
 			return "error_creating_array", errors.New("can't create an array of an empty subtype")
 		}
+		// encoding/json writes a []uint8 as a base64 string, rather than as an array of numbers
+		if subType == "uint8" {
+			subType = "uint16"
+		}
 		return "[]" + subType, nil
 	case "boolean":
 		return "bool", nil
@@ -286,7 +1179,8 @@ func getPrimitiveTypeName(schemaType string, subType string, pointer bool) (name
 	case "number":
 		return "float64", nil
 	case "null":
-		return "nil", nil
+		// the only valid value is null
+		return "interface{}", nil
 	case "object":
 		if subType == "" {
 			return "error_creating_object", errors.New("can't create an object of an empty subtype")
@@ -303,71 +1197,261 @@ func getPrimitiveTypeName(schemaType string, subType string, pointer bool) (name
 		schemaType, subType)
 }
 
+// formatType is the golang type used for strings with a "format", when FormatTypes is set.
+type formatType struct {
+	name string
+	// import path of the package declaring the type
+	pkg string
+}
+
+var formatTypes = map[string]formatType{
+	"date-time":     {name: "time.Time", pkg: "time"},
+	"date":          {name: "types.Date", pkg: typesImport},
+	"duration":      {name: "types.Duration", pkg: typesImport},
+	"uri":           {name: "types.URL", pkg: typesImport},
+	"uri-reference": {name: "types.URL", pkg: typesImport},
+	"uuid":          {name: "types.UUID", pkg: typesImport},
+	"ipv4":          {name: "net.IP", pkg: "net"},
+	"ipv6":          {name: "net.IP", pkg: "net"},
+	"byte":          {name: "[]byte"},
+}
+
+// getFormatTypeName returns the golang type for a string with the schema's "format" or "contentEncoding", or
+// defaultType if there isn't one.
+func (g *Generator) getFormatTypeName(schema *Schema, defaultType string) string {
+	if schema.ContentEncoding == "base64" {
+		return "[]byte"
+	}
+	t, ok := formatTypes[schema.Format]
+	if !ok {
+		return defaultType
+	}
+	if t.pkg != "" {
+		g.imports[t.pkg] = true
+	}
+	return t.name
+}
+
+// integerFormats are the integer "format" values which are golang integer types, as used by OpenAPI.
+var integerFormats = map[string]bool{
+	"int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+}
+
+// integerRange is the range of values held by a golang integer type.
+type integerRange struct {
+	name     string
+	min, max *big.Int
+}
+
+// integerRanges are in order of size, so that the first which holds a range of values is the narrowest.
+var integerRanges = []integerRange{
+	{name: "uint8", min: big.NewInt(0), max: big.NewInt(math.MaxUint8)},
+	{name: "int8", min: big.NewInt(math.MinInt8), max: big.NewInt(math.MaxInt8)},
+	{name: "uint16", min: big.NewInt(0), max: big.NewInt(math.MaxUint16)},
+	{name: "int16", min: big.NewInt(math.MinInt16), max: big.NewInt(math.MaxInt16)},
+	{name: "uint32", min: big.NewInt(0), max: big.NewInt(math.MaxUint32)},
+	{name: "int32", min: big.NewInt(math.MinInt32), max: big.NewInt(math.MaxInt32)},
+	{name: "uint64", min: big.NewInt(0), max: new(big.Int).SetUint64(math.MaxUint64)},
+	{name: "int64", min: big.NewInt(math.MinInt64), max: big.NewInt(math.MaxInt64)},
+}
+
+// int64Range is the range of an int, which is used for integers unless they're outside of it.
+var int64Range = integerRanges[len(integerRanges)-1]
+
+// getIntegerTypeName returns the golang type for an integer schema. Its "format", e.g. "int32", is used when it
+// names a golang integer type, and otherwise int, or the narrowest type which holds its bounds when
+// InferIntegerTypes is set. Integers with bounds outside of the range of an int64 are json.Number or *big.Int.
+func (g *Generator) getIntegerTypeName(schema *Schema) string {
+	if integerFormats[schema.Format] {
+		return schema.Format
+	}
+	min, hasMin := integerMinimum(schema)
+	max, hasMax := integerMaximum(schema)
+	if g.InferIntegerTypes && hasMin && hasMax {
+		for _, r := range integerRanges {
+			if min.Cmp(r.min) >= 0 && max.Cmp(r.max) <= 0 {
+				return r.name
+			}
+		}
+		return g.getBigIntegerTypeName()
+	}
+	if hasMin && min.Cmp(int64Range.min) < 0 || hasMax && max.Cmp(int64Range.max) > 0 {
+		return g.getBigIntegerTypeName()
+	}
+	return "int"
+}
+
+func (g *Generator) getBigIntegerTypeName() string {
+	if g.BigIntegers {
+		g.imports["math/big"] = true
+		return "*big.Int"
+	}
+	g.imports["encoding/json"] = true
+	return "json.Number"
+}
+
+// integerMinimum returns the smallest integer allowed by the minimum and exclusiveMinimum of the schema.
+func integerMinimum(schema *Schema) (min *big.Int, ok bool) {
+	if schema.Minimum != nil {
+		min = ceil(schema.exactNumber("minimum", *schema.Minimum))
+	}
+	if bound, exclusive := schema.ExclusiveMinimum(); exclusive {
+		// up to draft-04, exclusiveMinimum is a boolean which makes the minimum exclusive
+		keyword := "exclusiveMinimum"
+		if _, isBool := schema.ExclusiveMinimumValue.(bool); isBool {
+			keyword = "minimum"
+		}
+		next := floor(schema.exactNumber(keyword, bound))
+		next.Add(next, big.NewInt(1))
+		if min == nil || next.Cmp(min) > 0 {
+			min = next
+		}
+	}
+	return min, min != nil
+}
+
+// integerMaximum returns the largest integer allowed by the maximum and exclusiveMaximum of the schema.
+func integerMaximum(schema *Schema) (max *big.Int, ok bool) {
+	if schema.Maximum != nil {
+		max = floor(schema.exactNumber("maximum", *schema.Maximum))
+	}
+	if bound, exclusive := schema.ExclusiveMaximum(); exclusive {
+		keyword := "exclusiveMaximum"
+		if _, isBool := schema.ExclusiveMaximumValue.(bool); isBool {
+			keyword = "maximum"
+		}
+		previous := ceil(schema.exactNumber(keyword, bound))
+		previous.Sub(previous, big.NewInt(1))
+		if max == nil || previous.Cmp(max) < 0 {
+			max = previous
+		}
+	}
+	return max, max != nil
+}
+
+// exactNumber returns the value of a numeric keyword of the schema as it's written in the JSON that the schema was
+// parsed from, when it's available, as a float64 can't hold every integer, e.g. 9223372036854775807 becomes 2^63.
+func (schema *Schema) exactNumber(keyword string, value float64) *big.Float {
+	var object map[string]json.RawMessage
+	if raw := schema.rawDocument(); raw != nil && json.Unmarshal(raw, &object) == nil {
+		if text, ok := object[keyword]; ok {
+			// the keyword may have been changed since the schema was parsed
+			exact, _, err := big.ParseFloat(string(text), 10, 256, big.ToNearestEven)
+			if err == nil {
+				if f, _ := exact.Float64(); f == value {
+					return exact
+				}
+			}
+		}
+	}
+	return new(big.Float).SetFloat64(value)
+}
+
+// floor returns the largest integer less than or equal to f.
+func floor(f *big.Float) *big.Int {
+	i, _ := f.Int(nil)
+	if f.Sign() < 0 && new(big.Float).SetInt(i).Cmp(f) != 0 {
+		i.Sub(i, big.NewInt(1))
+	}
+	return i
+}
+
+// ceil returns the smallest integer greater than or equal to f.
+func ceil(f *big.Float) *big.Int {
+	i, _ := f.Int(nil)
+	if f.Sign() > 0 && new(big.Float).SetInt(i).Cmp(f) != 0 {
+		i.Add(i, big.NewInt(1))
+	}
+	return i
+}
+
 // return a name for this (sub-)schema.
 func (g *Generator) getSchemaName(keyName string, schema *Schema) string {
-	if len(schema.Title) > 0 {
-		return getGolangName(schema.Title)
+	if name := g.getGolangName(schema.Title); name != "" {
+		return name
 	}
-	if keyName != "" {
-		return getGolangName(keyName)
+	if name := g.getGolangName(keyName); name != "" {
+		return name
 	}
 	if schema.Parent == nil {
 		return "Root"
 	}
 	if schema.JSONKey != "" {
-		return getGolangName(schema.JSONKey)
+		return g.getGolangName(schema.JSONKey)
 	}
 	if schema.Parent != nil && schema.Parent.JSONKey != "" {
-		return getGolangName(schema.Parent.JSONKey + "Item")
+		return g.getGolangName(schema.Parent.JSONKey + "Item")
 	}
-	g.anonCount++
-	return fmt.Sprintf("Anonymous%d", g.anonCount)
+	return g.getAnonymousName(schema)
 }
 
-// getGolangName strips invalid characters out of golang struct or field names.
-func getGolangName(s string) string {
-	buf := bytes.NewBuffer([]byte{})
-	for i, v := range splitOnAll(s, isNotAGoNameCharacter) {
-		if i == 0 && strings.IndexAny(v, "0123456789") == 0 {
-			// Go types are not allowed to start with a number, lets prefix with an underscore.
-			buf.WriteRune('_')
-		}
-		buf.WriteString(capitaliseFirstLetter(v))
-	}
-	return buf.String()
+// reserveTypeName returns the name to use for the type generated from schema. When another schema's type already has
+// that name, it's prefixed with the names of the parent types until it's unique, e.g. "CustomerAddress".
+func (g *Generator) reserveTypeName(name string, schema *Schema) (string, error) {
+	return g.reserveName(name, g.schemaLocation(schema), schema)
 }
 
-func splitOnAll(s string, shouldSplit func(r rune) bool) []string {
-	rv := []string{}
-	buf := bytes.NewBuffer([]byte{})
-	for _, c := range s {
-		if shouldSplit(c) {
-			rv = append(rv, buf.String())
-			buf.Reset()
-		} else {
-			buf.WriteRune(c)
+// reserveName returns the name to use for a declaration generated from schema, such as a type or a constant, which is
+// identified by location. Names which clash are prefixed in the same way as reserveTypeName.
+func (g *Generator) reserveName(name string, location string, schema *Schema) (string, error) {
+	candidate := name
+	for parent := schema.Parent; ; parent = parent.Parent {
+		if existing, ok := g.typeNames[candidate]; !ok || existing == location {
+			g.typeNames[candidate] = location
+			return candidate, nil
+		}
+		for parent != nil && g.getParentTypeName(parent) == "" {
+			parent = parent.Parent
 		}
+		if parent == nil {
+			return "", fmt.Errorf("reserveTypeName: the type %s is generated from both \"%s\" and \"%s\"",
+				name, g.typeNames[name], location)
+		}
+		candidate = g.getParentTypeName(parent) + candidate
 	}
-	if buf.Len() > 0 {
-		rv = append(rv, buf.String())
+}
+
+// releaseTypeNames frees the names reserved for aliases which aren't generated after all.
+func (g *Generator) releaseTypeNames(aliases []Field) {
+	for _, alias := range aliases {
+		delete(g.typeNames, alias.Name)
 	}
-	return rv
 }
 
-func isNotAGoNameCharacter(r rune) bool {
-	if unicode.IsLetter(r) || unicode.IsDigit(r) {
-		return false
+// schemaLocation returns the URI of the schema, used to tell whether two schemas are the same.
+func (g *Generator) schemaLocation(schema *Schema) string {
+	return strings.TrimSuffix(schema.GetRoot().ID(), "#") + g.resolver.GetPath(schema)
+}
+
+// getParentTypeName returns the name used to prefix the types of a schema's sub-schemas when they clash with another
+// type, i.e. the name of the schema's own type, or failing that its title or key.
+func (g *Generator) getParentTypeName(schema *Schema) string {
+	if typ := strings.TrimPrefix(schema.GeneratedType, "*"); typ != "" && typ == getGolangName(typ) {
+		return typ
 	}
-	return true
+	if schema.Title != "" {
+		return g.getGolangName(schema.Title)
+	}
+	return g.getGolangName(schema.JSONKey)
 }
 
-func capitaliseFirstLetter(s string) string {
-	if s == "" {
-		return s
+// getAnonymousName names a schema which has no title or key after its path from the nearest ancestor which does, e.g.
+// "ItemsAdditionalProperties", so that the name doesn't change between runs.
+func (g *Generator) getAnonymousName(schema *Schema) string {
+	var elements []string
+	s := schema
+	for s.Parent != nil && s.Title == "" && s.JSONKey == "" {
+		elements = append([]string{s.PathElement}, elements...)
+		s = s.Parent
+	}
+	prefix := "Root"
+	if s.Title != "" {
+		prefix = s.Title
+	} else if s.JSONKey != "" {
+		prefix = s.JSONKey
 	}
-	prefix := s[0:1]
-	suffix := s[1:]
-	return strings.ToUpper(prefix) + suffix
+	return g.getGolangName(prefix + " " + strings.Join(elements, " "))
 }
 
 // Struct defines the data required to generate a struct in Go.
@@ -379,9 +1463,25 @@ type Struct struct {
 	// Description of the struct
 	Description string
 	Fields      map[string]Field
+	// The keys of Fields in the order they should be output, or empty for alphabetical order
+	FieldOrder []string
 
 	GenerateCode   bool
 	AdditionalType string
+	// The golang name of the function which returns the struct with its default values, e.g. "NewAddress", or empty
+	// when none of the fields have a default value
+	Constructor string
+
+	// the schema the struct was generated from
+	schema *Schema
+}
+
+// addField adds f to the struct, recording its position when the order of the fields is preserved.
+func (s *Struct) addField(f Field, preserveOrder bool) {
+	if _, ok := s.Fields[f.Name]; !ok && preserveOrder {
+		s.FieldOrder = append(s.FieldOrder, f.Name)
+	}
+	s.Fields[f.Name] = f
 }
 
 // Field defines the data required to generate a field in Go.
@@ -396,4 +1496,102 @@ type Field struct {
 	// Required is set to true when the field is required.
 	Required    bool
 	Description string
+
+	// the schema the field was generated from
+	schema *Schema
+	// the other declarations of the property within an allOf, whose constraints also apply
+	allOf []*Schema
+}
+
+// Union defines the data required to generate a tagged union in Go, i.e. a struct holding one of a number of types
+// that implement a common interface.
+type Union struct {
+	// The golang name, e.g. "Pet"
+	Name string
+	// Description of the union
+	Description string
+	// The golang name of the interface implemented by the variants, e.g. "PetValue"
+	Interface string
+	// The JSON property used to choose the variant when unmarshalling, e.g. "petType". When empty, each of the
+	// variants is tried in turn.
+	Discriminator string
+	Variants      []Variant
+
+	// the schema the union was generated from
+	schema *Schema
+}
+
+// Enum defines the data required to generate a named type with a constant for each of its permitted values.
+type Enum struct {
+	// The golang name, e.g. "Favouritecat"
+	Name string
+	// Description of the enum
+	Description string
+	// The golang type of the values, e.g. "string". When empty, the values are of different JSON types, and the
+	// enum holds their JSON encoding in a string.
+	Type   string
+	Values []EnumValue
+
+	// the schema the enum was generated from
+	schema *Schema
+}
+
+// EnumValue defines one of the constants of an Enum.
+type EnumValue struct {
+	// The golang name, e.g. "FavouritecatA"
+	Name string
+	// The golang literal, e.g. "\"A\""
+	Value string
+}
+
+func (e Enum) hasValue(literal string) bool {
+	for _, v := range e.Values {
+		if v.Value == literal {
+			return true
+		}
+	}
+	return false
+}
+
+func (e Enum) hasValueName(name string) bool {
+	for _, v := range e.Values {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Variant defines one of the types that a Union may hold.
+type Variant struct {
+	// The golang type, e.g. "*Cat"
+	Type string
+	// The value of the discriminator property which selects this variant, e.g. "cat"
+	DiscriminatorValue string
+}
+
+func (u Union) hasVariant(typ string) bool {
+	for _, v := range u.Variants {
+		if v.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Tuple defines the data required to generate a struct in Go for an array whose first items have different types,
+// e.g. [latitude, longitude]. The struct is marshalled as a JSON array.
+type Tuple struct {
+	// The golang name, e.g. "Point"
+	Name string
+	// Description of the tuple
+	Description string
+	// Items are the fields holding the first items of the array, in order, e.g. "Item1". The JSONName of each is its
+	// index.
+	Items []Field
+	// The golang type of the items which follow Items, e.g. "string". When empty, no more items are permitted.
+	RestType string
+
+	// the schema the tuple was generated from
+	schema *Schema
 }
@@ -1,7 +1,13 @@
 package generate
 
 import (
+	"bytes"
 	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -779,3 +785,672 @@ func TestTypeAliases(t *testing.T) {
 type Root struct {
 	Name interface{} `json:"name,omitempty"`
 }
+
+func TestThatAllOfSubSchemasAreMerged(t *testing.T) {
+	root := &Schema{
+		Title: "Dog",
+		AllOf: []*Schema{
+			{Reference: "#/definitions/animal"},
+			{
+				Properties: map[string]*Schema{
+					"breed": {TypeValue: "string"},
+					"name":  {TypeValue: "string"},
+				},
+				Required: []string{"breed"},
+			},
+		},
+		Definitions: map[string]*Schema{
+			"animal": {
+				TypeValue: "object",
+				Properties: map[string]*Schema{
+					"name": {TypeValue: "string"},
+					"legs": {TypeValue: "integer"},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+	root.Init()
+
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types: ", err)
+	}
+
+	dog, ok := g.Structs["Dog"]
+	if !ok {
+		t.Fatalf("Expected the Dog type to be generated, but only types %s were made.", strings.Join(getStructNamesFromMap(g.Structs), ", "))
+	}
+	if len(dog.Fields) != 3 {
+		t.Errorf("Expected 3 fields, got %d", len(dog.Fields))
+	}
+	testField(dog.Fields["Name"], "name", "Name", "string", true, t)
+	testField(dog.Fields["Legs"], "legs", "Legs", "int", false, t)
+	testField(dog.Fields["Breed"], "breed", "Breed", "string", true, t)
+	if len(g.Aliases) != 0 {
+		t.Errorf("Expected no aliases, got %d", len(g.Aliases))
+	}
+}
+
+func TestThatCircularAllOfReferencesAreMergedOnce(t *testing.T) {
+	root := &Schema{
+		Title: "Tree",
+		Properties: map[string]*Schema{
+			"root": {Reference: "#/definitions/node"},
+		},
+		Definitions: map[string]*Schema{
+			"node": {
+				AllOf: []*Schema{
+					{TypeValue: "object", Properties: map[string]*Schema{"value": {TypeValue: "string"}}},
+					{Reference: "#/definitions/node"},
+				},
+			},
+		},
+	}
+	root.Init()
+
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types: ", err)
+	}
+
+	node, ok := g.Structs["Node"]
+	if !ok {
+		t.Fatalf("Expected the Node type to be generated, but only types %s were made.", strings.Join(getStructNamesFromMap(g.Structs), ", "))
+	}
+	testField(node.Fields["Value"], "value", "Value", "string", false, t)
+}
+
+func TestThatConflictingAllOfPropertiesResultInAnError(t *testing.T) {
+	root := &Schema{
+		Title: "Conflict",
+		AllOf: []*Schema{
+			{Properties: map[string]*Schema{"id": {TypeValue: "string"}}},
+			{Properties: map[string]*Schema{"id": {TypeValue: "integer"}}},
+		},
+	}
+	root.Init()
+
+	g := New(root)
+	err := g.CreateTypes()
+	if err == nil {
+		t.Fatal("Expected an error for the conflicting property types, but got nil")
+	}
+	if !strings.Contains(err.Error(), "#/allOf/0/properties/id") || !strings.Contains(err.Error(), "#/allOf/1/properties/id") {
+		t.Errorf("Expected the error to contain both schema paths, got: %v", err)
+	}
+}
+
+func TestThatConflictingAllOfPropertiesWithinDefinitionsResultInAnError(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root",
+		"properties": { "c": { "$ref": "#/definitions/conf" } },
+		"definitions": {
+			"base": { "type": "object", "properties": { "n": { "type": "integer" } } },
+			"conf": { "allOf": [ { "$ref": "#/definitions/base" }, { "properties": { "n": { "type": "string" } } } ] }
+		} }`, &url.URL{Scheme: "file", Path: "generator_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = New(root).CreateTypes()
+	if err == nil || !strings.Contains(err.Error(), "conflicting types") {
+		t.Errorf("expected an error for the conflicting property types, got %v", err)
+	}
+}
+
+func TestThatTheConstraintsOfEachAllOfDeclarationAreValidated(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root",
+		"allOf": [
+			{ "properties": { "n": { "type": "string", "minLength": 1 } } },
+			{ "properties": { "n": { "type": "string", "maxLength": 3 } } }
+		] }`, &url.URL{Scheme: "file", Path: "generator_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(root)
+	g.GenerateValidation = true
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types: ", err)
+	}
+	buf := new(bytes.Buffer)
+	if err := Output(buf, g, "test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"#/allOf/0/properties/n/minLength", "#/allOf/1/properties/n/maxLength"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected the code to validate %s, got:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestThatOneOfSubSchemasAreGeneratedAsAUnion(t *testing.T) {
+	root := &Schema{
+		Title: "Shape",
+		OneOf: []*Schema{
+			{Reference: "#/definitions/circle"},
+			{Reference: "#/definitions/square"},
+			{TypeValue: "string"},
+		},
+		Definitions: map[string]*Schema{
+			"circle": {
+				TypeValue: "object",
+				Properties: map[string]*Schema{
+					"kind":   {Const: "circle"},
+					"radius": {TypeValue: "number"},
+				},
+			},
+			"square": {
+				TypeValue: "object",
+				Properties: map[string]*Schema{
+					"kind": {Const: "square"},
+					"side": {TypeValue: "number"},
+				},
+			},
+		},
+	}
+	root.Init()
+
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types: ", err)
+	}
+
+	u, ok := g.Unions["Shape"]
+	if !ok {
+		t.Fatal("Expected the Shape union to be generated")
+	}
+	expected := []Variant{
+		{Type: "*Circle"},
+		{Type: "*Square"},
+		{Type: "ShapeString"},
+	}
+	if !reflect.DeepEqual(u.Variants, expected) {
+		t.Errorf("expected variants %v, got %v", expected, u.Variants)
+	}
+	if u.Discriminator != "" {
+		t.Errorf("the string variant has no kind property, so no discriminator was expected, got %q", u.Discriminator)
+	}
+	if g.Aliases["ShapeString"].Type != "string" {
+		t.Errorf("expected the ShapeString alias to be a string, got %q", g.Aliases["ShapeString"].Type)
+	}
+}
+
+func TestThatSharedConstPropertiesAreUsedAsTheDiscriminator(t *testing.T) {
+	root := &Schema{
+		Title: "Shape",
+		AnyOf: []*Schema{
+			{Title: "circle", TypeValue: "object", Properties: map[string]*Schema{"kind": {Const: "c"}}},
+			{Title: "square", TypeValue: "object", Properties: map[string]*Schema{"kind": {Const: "s"}}},
+		},
+	}
+	root.Init()
+
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types: ", err)
+	}
+
+	u := g.Unions["Shape"]
+	if u.Discriminator != "kind" {
+		t.Errorf("expected the discriminator to be 'kind', got %q", u.Discriminator)
+	}
+	if u.Variants[0].DiscriminatorValue != "c" || u.Variants[1].DiscriminatorValue != "s" {
+		t.Errorf("unexpected discriminator values: %v", u.Variants)
+	}
+}
+
+func TestThatUnionsWithoutTypedVariantsAreGeneratedAsEmptyInterfaces(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root", "type": "object", "properties": {
+		"a": { "oneOf": [ { "type": "null" } ] },
+		"b": { "oneOf": [ { "type": "string" }, {} ] } } }`, &url.URL{Scheme: "file", Path: "/root.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types: ", err)
+	}
+
+	for _, name := range []string{"A", "B"} {
+		if typ := g.Structs["Root"].Fields[name].Type; typ != "interface{}" {
+			t.Errorf("expected %s to be an interface{}, got %q", name, typ)
+		}
+	}
+	if len(g.Unions) > 0 || len(g.Aliases) > 0 {
+		t.Errorf("expected no unions or aliases, got %v and %v", g.Unions, g.Aliases)
+	}
+}
+
+func TestThatRepeatedUnionVariantsAreGeneratedOnce(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root", "type": "object", "properties": {
+		"pet": { "oneOf": [ { "$ref": "#/definitions/cat" }, { "$ref": "#/definitions/dog" }, { "$ref": "#/definitions/cat" } ] } },
+		"definitions": {
+			"cat": { "type": "object", "properties": { "lives": { "type": "integer" } } },
+			"dog": { "type": "object", "properties": { "breed": { "type": "string" } } } } }`,
+		&url.URL{Scheme: "file", Path: "/root.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal("Failed to create types: ", err)
+	}
+
+	expected := []Variant{{Type: "*Cat"}, {Type: "*Dog"}}
+	if actual := g.Unions["Pet"].Variants; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected variants %v, got %v", expected, actual)
+	}
+	var buf bytes.Buffer
+	if err := Output(&buf, g, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "func (*Cat) isPet()"); n != 1 {
+		t.Errorf("expected the isPet method of Cat to be written once, got %d in:\n%s", n, buf.String())
+	}
+}
+
+func TestThatEnumsAreGeneratedAsNamedTypes(t *testing.T) {
+	tests := []struct {
+		input    *Schema
+		expected Enum
+	}{
+		{
+			input: &Schema{TypeValue: "string", Enum: []interface{}{"a", "b-c", ""}},
+			expected: Enum{Name: "Root", Type: "string", Values: []EnumValue{
+				{Name: "RootA", Value: `"a"`},
+				{Name: "RootBC", Value: `"b-c"`},
+				{Name: "RootEmpty", Value: `""`},
+			}},
+		},
+		{
+			input: &Schema{TypeValue: "integer", Enum: []interface{}{-1.0, 2.0}},
+			expected: Enum{Name: "Root", Type: "int", Values: []EnumValue{
+				{Name: "RootMinus1", Value: "-1"},
+				{Name: "Root2", Value: "2"},
+			}},
+		},
+		{
+			input: &Schema{Enum: []interface{}{1.5, 2.0}},
+			expected: Enum{Name: "Root", Type: "float64", Values: []EnumValue{
+				{Name: "Root1Point5", Value: "1.5"},
+				{Name: "Root2", Value: "2"},
+			}},
+		},
+		{
+			input: &Schema{Enum: []interface{}{"a", 1.0, nil}},
+			expected: Enum{Name: "Root", Type: "", Values: []EnumValue{
+				{Name: "RootA", Value: `"\"a\""`},
+				{Name: "Root1", Value: `"1"`},
+				{Name: "RootNull", Value: `"null"`},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		test.input.Init()
+		g := New(test.input)
+		if err := g.CreateTypes(); err != nil {
+			t.Fatal(err)
+		}
+		actual := g.Enums["Root"]
+		if actual.Name != test.expected.Name || actual.Type != test.expected.Type || !reflect.DeepEqual(actual.Values, test.expected.Values) {
+			t.Errorf("expected %+v, got %+v", test.expected, actual)
+		}
+		if len(g.Aliases) != 0 {
+			t.Errorf("expected no aliases, got %d", len(g.Aliases))
+		}
+	}
+}
+
+func TestThatRefSiblingsAreOnlyMergedFromDraft201909(t *testing.T) {
+	tests := []struct {
+		schema         string
+		expectedFields int
+	}{
+		{schema: "http://json-schema.org/draft-07/schema#", expectedFields: 1},
+		{schema: "https://json-schema.org/draft/2019-09/schema", expectedFields: 2},
+	}
+
+	for _, test := range tests {
+		root := &Schema{
+			SchemaType: test.schema,
+			Title:      "Root",
+			Properties: map[string]*Schema{
+				"dog": {
+					Reference:  "#/definitions/animal",
+					Properties: map[string]*Schema{"breed": {TypeValue: "string"}},
+				},
+			},
+			Definitions: map[string]*Schema{
+				"animal": {
+					TypeValue:  "object",
+					Properties: map[string]*Schema{"name": {TypeValue: "string"}},
+				},
+			},
+		}
+		root.Init()
+
+		g := New(root)
+		if err := g.CreateTypes(); err != nil {
+			t.Fatal(err)
+		}
+		dogType := strings.TrimPrefix(g.Structs["Root"].Fields["Dog"].Type, "*")
+		if actual := len(g.Structs[dogType].Fields); actual != test.expectedFields {
+			t.Errorf("%s: expected %d fields, got %d", test.schema, test.expectedFields, actual)
+		}
+	}
+}
+
+func TestThatFormatsAreOnlyMappedWhenEnabled(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		root := &Schema{
+			Title: "Root",
+			Properties: map[string]*Schema{
+				"created": {TypeValue: "string", Format: "date-time"},
+				"data":    {TypeValue: "string", ContentEncoding: "base64"},
+				"name":    {TypeValue: "string", Format: "hostname"},
+			},
+		}
+		root.Init()
+
+		g := New(root)
+		g.FormatTypes = enabled
+		if err := g.CreateTypes(); err != nil {
+			t.Fatal(err)
+		}
+		fields := g.Structs["Root"].Fields
+		expected := map[string]string{"Created": "string", "Data": "string", "Name": "string"}
+		if enabled {
+			expected = map[string]string{"Created": "time.Time", "Data": "[]byte", "Name": "string"}
+		}
+		for name, typ := range expected {
+			if fields[name].Type != typ {
+				t.Errorf("enabled=%v: expected %s to be %s, got %s", enabled, name, typ, fields[name].Type)
+			}
+		}
+		if g.imports["time"] != enabled {
+			t.Errorf("enabled=%v: expected the time import to be %v", enabled, enabled)
+		}
+	}
+}
+
+func TestThatFieldOrderIsOnlyKeptWhenEnabled(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		root, err := Parse(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"title": "Root",
+			"properties": {
+				"name": { "type": "string" },
+				"age": { "type": "integer" }
+			},
+			"additionalProperties": { "type": "string" }
+		}`, &url.URL{Scheme: "file", Path: "generator_test.go"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		g := New(root)
+		g.PreserveOrder = enabled
+		if err := g.CreateTypes(); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"AdditionalProperties", "Age", "Name"}
+		if enabled {
+			expected = []string{"Name", "Age", "AdditionalProperties"}
+		}
+		if actual := g.Structs["Root"].fieldNames(); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("enabled=%v: expected %v, got %v", enabled, expected, actual)
+		}
+	}
+}
+
+func TestThatAnonymousSchemasAreNamedAfterTheirPath(t *testing.T) {
+	root, err := Parse(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "Root",
+		"properties": {
+			"items": {
+				"type": "object",
+				"additionalProperties": {
+					"type": "object",
+					"additionalProperties": {
+						"type": "object",
+						"properties": { "name": { "type": "string" } }
+					}
+				}
+			}
+		}
+	}`, &url.URL{Scheme: "file", Path: "generator_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.Structs["ItemsAdditionalPropertiesAdditionalProperties"]; !ok {
+		t.Errorf("expected a struct named after its path, got %v", getOrderedStructNames(g.Structs))
+	}
+}
+
+func TestThatClashingRootTypeNamesResultInAnError(t *testing.T) {
+	var schemas []*Schema
+	for _, path := range []string{"a.json", "b.json"} {
+		s, err := Parse(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"properties": { "name": { "type": "string" } }
+		}`, &url.URL{Scheme: "file", Path: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		schemas = append(schemas, s)
+	}
+
+	err := New(schemas...).CreateTypes()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, expected := range []string{"file://a.json#", "file://b.json#"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected the error to contain %q, got: %v", expected, err)
+		}
+	}
+}
+
+func TestThatClashingGoNamesResultInAnError(t *testing.T) {
+	root, err := Parse(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "Root",
+		"properties": {
+			"a": { "type": "string", "x-go-name": "Value" },
+			"b": { "type": "string", "x-go-name": "Value" }
+		}
+	}`, &url.URL{Scheme: "file", Path: "generator_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = New(root).CreateTypes()
+	if err == nil || !strings.Contains(err.Error(), `x-go-name Value of property "b" is already used by "a"`) {
+		t.Errorf("expected a clash to be reported, got: %v", err)
+	}
+}
+
+func TestThatOptionalPropertiesFollowThePolicy(t *testing.T) {
+	tests := []struct {
+		policy   OptionalPolicy
+		expected map[string]string
+	}{
+		{
+			policy:   OptionalValues,
+			expected: map[string]string{"Count": "int", "Label": "*string", "Name": "string", "Nothing": "interface{}"},
+		},
+		{
+			policy:   OptionalPointers,
+			expected: map[string]string{"Count": "*int", "Label": "*string", "Name": "string", "Nothing": "interface{}"},
+		},
+		{
+			policy:   OptionalGeneric,
+			expected: map[string]string{"Count": "types.Optional[int]", "Label": "types.Optional[string]", "Name": "string", "Nothing": "interface{}"},
+		},
+		{
+			policy:   OptionalTriState,
+			expected: map[string]string{"Count": "types.Nullable[int]", "Label": "types.Nullable[string]", "Name": "string", "Nothing": "types.Nullable[interface{}]"},
+		},
+	}
+	for _, test := range tests {
+		root, err := Parse(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"title": "Root",
+			"properties": {
+				"count": { "type": "integer" },
+				"label": { "type": ["string", "null"] },
+				"name": { "type": "string" },
+				"nothing": { "type": "null" }
+			},
+			"required": ["label", "name"]
+		}`, &url.URL{Scheme: "file", Path: "generator_test.go"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		g := New(root)
+		g.OptionalPolicy = test.policy
+		if err := g.CreateTypes(); err != nil {
+			t.Fatal(err)
+		}
+		for name, typ := range test.expected {
+			if actual := g.Structs["Root"].Fields[name].Type; actual != typ {
+				t.Errorf("policy %v: expected %s to be %s, got %s", test.policy, name, typ, actual)
+			}
+		}
+	}
+}
+
+func TestThatIntegerTypesAreInferredFromTheirBounds(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	tests := []struct {
+		schema   *Schema
+		infer    bool
+		big      bool
+		expected string
+	}{
+		{schema: &Schema{}, expected: "int"},
+		{schema: &Schema{Format: "uint16"}, expected: "uint16"},
+		{schema: &Schema{Format: "int64", Minimum: f(0), Maximum: f(10)}, infer: true, expected: "int64"},
+		{schema: &Schema{Format: "decimal"}, expected: "int"},
+		{schema: &Schema{Minimum: f(0), Maximum: f(255)}, expected: "int"},
+		{schema: &Schema{Minimum: f(0), Maximum: f(255)}, infer: true, expected: "uint8"},
+		{schema: &Schema{Minimum: f(0), Maximum: f(256)}, infer: true, expected: "uint16"},
+		{schema: &Schema{Minimum: f(-1), Maximum: f(127)}, infer: true, expected: "int8"},
+		{schema: &Schema{Minimum: f(-1), ExclusiveMaximumValue: 128.0}, infer: true, expected: "int8"},
+		{schema: &Schema{ExclusiveMinimumValue: -1.0, Maximum: f(255)}, infer: true, expected: "uint8"},
+		{schema: &Schema{Minimum: f(-1), Maximum: f(255), ExclusiveMinimumValue: true}, infer: true, expected: "uint8"},
+		{schema: &Schema{Minimum: f(0), Maximum: f(math.Exp2(40))}, infer: true, expected: "uint64"},
+		{schema: &Schema{Minimum: f(-1), Maximum: f(math.Exp2(40))}, infer: true, expected: "int64"},
+		{schema: &Schema{Minimum: f(0)}, infer: true, expected: "int"},
+		{schema: &Schema{Minimum: f(0), Maximum: f(1e30)}, infer: true, expected: "json.Number"},
+		{schema: &Schema{Maximum: f(1e30)}, expected: "json.Number"},
+		{schema: &Schema{Minimum: f(-1e30)}, big: true, expected: "*big.Int"},
+	}
+	for i, test := range tests {
+		g := New()
+		g.InferIntegerTypes = test.infer
+		g.BigIntegers = test.big
+		if actual := g.getIntegerTypeName(test.schema); actual != test.expected {
+			t.Errorf("test %d: expected %s, got %s", i, test.expected, actual)
+		}
+	}
+}
+
+func TestThatIntegerBoundsAreComparedExactly(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root", "properties": {
+		"int64": { "type": "integer", "minimum": -9223372036854775808, "maximum": 9223372036854775807 },
+		"overInt64": { "type": "integer", "maximum": 9223372036854775808 },
+		"uint64": { "type": "integer", "minimum": 0, "maximum": 18446744073709551615 },
+		"overUint64": { "type": "integer", "minimum": 0, "maximum": 18446744073709551616 },
+		"bytes": { "type": "array", "items": { "type": "integer", "format": "uint8" } } } }`,
+		&url.URL{Scheme: "file", Path: "generator_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		infer    bool
+		expected map[string]string
+	}{
+		{
+			expected: map[string]string{"Int64": "int", "OverInt64": "json.Number", "Uint64": "json.Number",
+				"OverUint64": "json.Number", "Bytes": "[]uint16"},
+		},
+		{
+			infer: true,
+			expected: map[string]string{"Int64": "int64", "OverInt64": "json.Number", "Uint64": "uint64",
+				"OverUint64": "json.Number", "Bytes": "[]uint16"},
+		},
+	}
+	for _, test := range tests {
+		g := New(root)
+		g.InferIntegerTypes = test.infer
+		if err := g.CreateTypes(); err != nil {
+			t.Fatal(err)
+		}
+		for name, typ := range test.expected {
+			if actual := g.Structs["Root"].Fields[name].Type; actual != typ {
+				t.Errorf("infer %v: expected %s to be %s, got %s", test.infer, name, typ, actual)
+			}
+		}
+	}
+}
+
+func TestThatReferencedFilesAreLoadedOnDemand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"root.json":  `{ "title": "Root", "properties": { "a": { "$ref": "a.json" }, "b": { "$ref": "sub/b.json#/definitions/b" } } }`,
+		"a.json":     `{ "$id": "http://example.com/a.json", "title": "A", "properties": { "b": { "$ref": "#/definitions/b" } }, "definitions": { "b": { "type": "integer" } } }`,
+		"sub/b.json": `{ "definitions": { "b": { "title": "B", "properties": { "c": { "$ref": "../missing.json" } } } } }`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	schemas, err := ReadInputFiles([]string{filepath.Join(dir, "root.json")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(schemas...)
+	err = g.CreateTypes()
+	expected := `processReference: reference "../missing.json" at "#/definitions/b/properties/c" can't be resolved: failed to load file://` +
+		filepath.ToSlash(filepath.Join(dir, "missing.json"))
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("expected an error starting %q, got %v", expected, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "missing.json"), []byte(`{ "type": "string" }`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g = New(schemas...)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Structs["A"].Fields["B"].Type != "int" || g.Structs["B"].Fields["C"].Type != "string" {
+		t.Errorf("unexpected types: %+v", g.Structs)
+	}
+	if g.Structs["Root"].Fields["A"].Type != "*A" || g.Structs["Root"].Fields["B"].Type != "*B" {
+		t.Errorf("unexpected types: %+v", g.Structs["Root"])
+	}
+}
@@ -8,50 +8,86 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 )
 
 // ReadInputFiles from disk and convert to JSON schema.
 func ReadInputFiles(inputFiles []string, schemaKeyRequired bool) ([]*Schema, error) {
+	return ReadInputFilesWithCatalog(inputFiles, schemaKeyRequired, nil)
+}
+
+// ReadInputFilesWithCatalog reads JSON schemas from disk, using the catalog to read those passed as URIs from their
+// local paths. Files within the directories of the catalog without an $id are identified by their URI, rather than
+// their file URI, so that relative references within them resolve in the same way as they do once published.
+func ReadInputFilesWithCatalog(inputFiles []string, schemaKeyRequired bool, catalog Catalog) ([]*Schema, error) {
 	schemas := make([]*Schema, len(inputFiles))
 	for i, file := range inputFiles {
-		b, err := ioutil.ReadFile(file)
+		var err error
+		schemas[i], err = readInputFile(file, schemaKeyRequired, catalog)
 		if err != nil {
-			return nil, errors.New("failed to read the input file with error " + err.Error())
+			return nil, err
 		}
+	}
 
-		abPath, err := abs(file)
-		if err != nil {
-			return nil, errors.New("failed to normalise input path with error " + err.Error())
+	return schemas, nil
+}
+
+// readInputFile reads a JSON schema from disk, identified by its file URI, or its URI in the catalog, unless it has
+// an $id.
+func readInputFile(file string, schemaKeyRequired bool, catalog Catalog) (*Schema, error) {
+	// a single letter scheme is a Windows drive, e.g. "C:\schema.json"
+	if u, err := url.Parse(file); err == nil && u.IsAbs() && len(u.Scheme) > 1 {
+		if p, ok := catalog.Path(u); ok {
+			file = p
 		}
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.New("failed to read the input file with error " + err.Error())
+	}
 
-		fileURI := url.URL{
-			Scheme: "file",
-			Path:   abPath,
+	abPath, err := abs(file)
+	if err != nil {
+		return nil, errors.New("failed to normalise input path with error " + err.Error())
+	}
+
+	fileURI := &url.URL{
+		Scheme: "file",
+		Path:   abPath,
+	}
+	if uri, ok := catalog.URI(filepath.FromSlash(abPath)); ok {
+		if fileURI, err = url.Parse(uri); err != nil {
+			return nil, errors.New("failed to parse the URI of the input file in the catalog with error " + err.Error())
 		}
+	}
 
-		schemas[i], err = ParseWithSchemaKeyRequired(string(b), &fileURI, schemaKeyRequired)
-		if err != nil {
-			if jsonError, ok := err.(*json.SyntaxError); ok {
-				line, character, lcErr := lineAndCharacter(b, int(jsonError.Offset))
-				errStr := fmt.Sprintf("cannot parse JSON schema due to a syntax error at %s line %d, character %d: %v\n", file, line, character, jsonError.Error())
-				if lcErr != nil {
-					errStr += fmt.Sprintf("couldn't find the line and character position of the error due to error %v\n", lcErr)
-				}
-				return nil, errors.New(errStr)
+	return parseInputFile(b, file, fileURI, schemaKeyRequired)
+}
+
+// parseInputFile parses the JSON schema read from the file, reporting the position of any syntax errors.
+func parseInputFile(b []byte, file string, uri *url.URL, schemaKeyRequired bool) (*Schema, error) {
+	schema, err := ParseWithSchemaKeyRequired(string(b), uri, schemaKeyRequired)
+	if err != nil {
+		if jsonError, ok := err.(*json.SyntaxError); ok {
+			line, character, lcErr := lineAndCharacter(b, int(jsonError.Offset))
+			errStr := fmt.Sprintf("cannot parse JSON schema due to a syntax error at %s line %d, character %d: %v\n", file, line, character, jsonError.Error())
+			if lcErr != nil {
+				errStr += fmt.Sprintf("couldn't find the line and character position of the error due to error %v\n", lcErr)
 			}
-			if jsonError, ok := err.(*json.UnmarshalTypeError); ok {
-				line, character, lcErr := lineAndCharacter(b, int(jsonError.Offset))
-				errStr := fmt.Sprintf("the JSON type '%v' cannot be converted into the Go '%v' type on struct '%s', field '%v'. See input file %s line %d, character %d\n", jsonError.Value, jsonError.Type.Name(), jsonError.Struct, jsonError.Field, file, line, character)
-				if lcErr != nil {
-					errStr += fmt.Sprintf("couldn't find the line and character position of the error due to error %v\n", lcErr)
-				}
-				return nil, errors.New(errStr)
+			return nil, errors.New(errStr)
+		}
+		if jsonError, ok := err.(*json.UnmarshalTypeError); ok {
+			line, character, lcErr := lineAndCharacter(b, int(jsonError.Offset))
+			errStr := fmt.Sprintf("the JSON type '%v' cannot be converted into the Go '%v' type on struct '%s', field '%v'. See input file %s line %d, character %d\n", jsonError.Value, jsonError.Type.Name(), jsonError.Struct, jsonError.Field, file, line, character)
+			if lcErr != nil {
+				errStr += fmt.Sprintf("couldn't find the line and character position of the error due to error %v\n", lcErr)
 			}
-			return nil, fmt.Errorf("failed to parse the input JSON schema file %s with error %v", file, err)
+			return nil, errors.New(errStr)
 		}
+		return nil, fmt.Errorf("failed to parse the input JSON schema file %s with error %v", file, err)
 	}
-
-	return schemas, nil
+	return schema, nil
 }
 
 func lineAndCharacter(bytes []byte, offset int) (line int, character int, err error) {
@@ -1,9 +1,11 @@
 package generate
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/url"
+	"strings"
 )
 
 // AdditionalProperties handles additional properties present in the JSON schema.
@@ -28,10 +30,18 @@ type Schema struct {
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.1.1
 	TypeValue interface{} `json:"type"`
 
+	// Anchor names the schema, so that it can be referenced as a plain-name fragment, e.g. "#address".
+	// https://json-schema.org/draft/2020-12/json-schema-core.html#rfc.section.8.2.2
+	Anchor string `json:"$anchor"`
+
 	// Definitions are inline re-usable schemas.
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.9
 	Definitions map[string]*Schema
 
+	// Defs are inline re-usable schemas, replacing Definitions from draft 2019-09 onwards.
+	// https://json-schema.org/draft/2020-12/json-schema-core.html#rfc.section.8.2.4
+	Defs map[string]*Schema `json:"$defs"`
+
 	// Properties, Required and AdditionalProperties describe an object's child instances.
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.5
 	Properties map[string]*Schema
@@ -43,10 +53,90 @@ type Schema struct {
 	// "additionalProperties": false
 	AdditionalPropertiesBool *bool `json:"-"`
 
+	// UnevaluatedProperties applies to the properties which aren't described by any sub-schema, e.g. of an allOf.
+	// "unevaluatedProperties": false closes the object in the same way as "additionalProperties": false.
+	// https://json-schema.org/draft/2020-12/json-schema-core.html#rfc.section.11.3
+	UnevaluatedProperties *AdditionalProperties
+
 	AnyOf []*Schema
 	AllOf []*Schema
 	OneOf []*Schema
 
+	// Discriminator names the property used to select one of the OneOf or AnyOf sub-schemas (OpenAPI extension).
+	// https://spec.openapis.org/oas/v3.0.3#discriminator-object
+	Discriminator *Discriminator
+
+	// Not, If, Then and Else apply sub-schemas conditionally.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.6
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.7.4
+	Not  *Schema
+	If   *Schema
+	Then *Schema
+	Else *Schema
+
+	// Const restricts the instance to a single value.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.1.3
+	Const interface{}
+
+	// Enum restricts the instance to one of a fixed set of values.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.1.2
+	Enum []interface{}
+
+	// MultipleOf, Maximum, ExclusiveMaximum, Minimum and ExclusiveMinimum validate numeric instances.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.2
+	MultipleOf *float64
+	Maximum    *float64
+	Minimum    *float64
+
+	// "exclusiveMaximum": true (up to draft-04), or "exclusiveMaximum": 10 (from draft-06 onwards)
+	ExclusiveMaximumValue interface{} `json:"exclusiveMaximum"`
+	ExclusiveMinimumValue interface{} `json:"exclusiveMinimum"`
+
+	// MaxLength, MinLength and Pattern validate strings.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.3
+	MaxLength *int
+	MinLength *int
+	Pattern   string
+
+	// MaxItems, MinItems, UniqueItems and Contains validate arrays.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.4
+	MaxItems    *int
+	MinItems    *int
+	UniqueItems bool
+	Contains    *Schema
+
+	// MaxProperties, MinProperties, PatternProperties, Dependencies and PropertyNames validate objects.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.5
+	MaxProperties     *int
+	MinProperties     *int
+	PatternProperties map[string]*Schema
+	Dependencies      map[string]*Dependency
+	PropertyNames     *Schema
+
+	// DependentRequired and DependentSchemas replace Dependencies from draft 2019-09 onwards.
+	// https://json-schema.org/draft/2020-12/json-schema-validation.html#rfc.section.6.5.4
+	// https://json-schema.org/draft/2020-12/json-schema-core.html#rfc.section.10.2.2.4
+	DependentRequired map[string][]string
+	DependentSchemas  map[string]*Schema
+
+	// Format is a semantic validation of the instance, e.g. "date-time".
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.7
+	Format string
+
+	// ContentEncoding and ContentMediaType describe non-JSON data held in a string.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.8
+	ContentEncoding  string
+	ContentMediaType string
+
+	// ReadOnly and WriteOnly describe how the instance may be used.
+	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.10.3
+	ReadOnly  bool
+	WriteOnly bool
+
+	// Comment is a note for schema maintainers.
+	// http://json-schema.org/draft-07/json-schema-core.html#rfc.section.9
+	Comment string `json:"$comment"`
+
 	// Default can be used to supply a default JSON value associated with a particular schema.
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.10.2
 	Default interface{}
@@ -55,14 +145,26 @@ type Schema struct {
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.10.4
 	Examples []interface{}
 
+	// Extensions holds the vendor extension keywords, which start with "x-", e.g. "x-go-type".
+	Extensions map[string]interface{} `json:"-"`
+
 	// Reference is a URI reference to a schema.
 	// http://json-schema.org/draft-07/json-schema-core.html#rfc.section.8
 	Reference string `json:"$ref"`
 
-	// Items represents the types that are permitted in the array.
+	// Items represents the types that are permitted in the array. When PrefixItems is set, Items applies to the
+	// items that follow them.
 	// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.4
 	Items *Schema
 
+	// "items": false, i.e. no items are permitted after the PrefixItems
+	ItemsBool *bool `json:"-"`
+
+	// PrefixItems are the schemas of the first items of the array, in order, i.e. a tuple. Up to draft 2019-09, the
+	// tuple is written as an array of "items", followed by "additionalItems", and is stored here in the same way.
+	// https://json-schema.org/draft/2020-12/json-schema-core.html#rfc.section.10.3.1.1
+	PrefixItems []*Schema
+
 	// NameCount is the number of times the instance name was encountered across the schema.
 	NameCount int `json:"-" `
 
@@ -77,6 +179,271 @@ type Schema struct {
 
 	// calculated struct name of this object, cached here
 	GeneratedType string `json:"-"`
+
+	// true when PrefixItems and Items were written as "items" and "additionalItems"
+	itemsArray bool
+
+	// true when the document was parsed requiring a $schema key, so that the documents it references are too
+	schemaKeyRequired bool
+
+	// the JSON that the document was parsed from, used to evaluate JSON Pointers
+	raw json.RawMessage
+
+	// the keys of Properties, Definitions and Defs in the order they were written
+	propertyOrder   []string
+	definitionOrder []string
+	defsOrder       []string
+}
+
+// UnmarshalJSON handles unmarshalling a Schema from JSON.
+func (schema *Schema) UnmarshalJSON(data []byte) error {
+	type schemaFields Schema
+	s := struct {
+		*schemaFields
+		Items           json.RawMessage
+		AdditionalItems json.RawMessage
+	}{
+		schemaFields: (*schemaFields)(schema),
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	if err := schema.unmarshalExtensions(keywords); err != nil {
+		return err
+	}
+	if err := schema.unmarshalKeyOrder(keywords); err != nil {
+		return err
+	}
+	items := s.Items
+	if len(items) > 0 && items[0] == '[' {
+		// the tuple form of "items" used up to draft 2019-09
+		if err := json.Unmarshal(items, &schema.PrefixItems); err != nil {
+			return err
+		}
+		schema.itemsArray = true
+		items = s.AdditionalItems
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	var b bool
+	if err := json.Unmarshal(items, &b); err == nil {
+		if !b {
+			schema.ItemsBool = &b
+		}
+		return nil
+	}
+	schema.Items = &Schema{}
+	return json.Unmarshal(items, schema.Items)
+}
+
+// unmarshalExtensions stores the vendor extension keywords, e.g. "x-go-type", in Extensions.
+func (schema *Schema) unmarshalExtensions(keywords map[string]json.RawMessage) error {
+	for k, v := range keywords {
+		if !strings.HasPrefix(k, "x-") {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(v, &value); err != nil {
+			return err
+		}
+		if schema.Extensions == nil {
+			schema.Extensions = make(map[string]interface{})
+		}
+		schema.Extensions[k] = value
+	}
+	return nil
+}
+
+// unmarshalKeyOrder records the order of the keys in "properties", "definitions" and "$defs", which is lost when
+// they're unmarshalled into maps.
+func (schema *Schema) unmarshalKeyOrder(keywords map[string]json.RawMessage) (err error) {
+	if schema.propertyOrder, err = objectKeys(keywords["properties"]); err != nil {
+		return err
+	}
+	if schema.definitionOrder, err = objectKeys(keywords["definitions"]); err != nil {
+		return err
+	}
+	schema.defsOrder, err = objectKeys(keywords["$defs"])
+	return err
+}
+
+// objectKeys returns the keys of the JSON object in data in the order they're written, or nil if data isn't an object.
+func objectKeys(data []byte) ([]string, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, err
+	}
+	var keys []string
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// orderedKeys returns the keys of m in the given order, followed by any others in alphabetical order.
+func orderedKeys(m map[string]*Schema, order []string) []string {
+	keys := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, k := range order {
+		if _, ok := m[k]; ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	for _, k := range getOrderedSchemaKeys(m) {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// propertyKeys returns the keys of Properties in the order they were written.
+func (schema *Schema) propertyKeys() []string {
+	return orderedKeys(schema.Properties, schema.propertyOrder)
+}
+
+// definitionKeys returns the keys of definitionSchemas() in the order they were written, "definitions" first.
+func (schema *Schema) definitionKeys() []string {
+	return orderedKeys(schema.definitionSchemas(), append(append([]string{}, schema.definitionOrder...), schema.defsOrder...))
+}
+
+// Draft is a version of the JSON schema specification.
+type Draft int
+
+// The versions of the JSON schema specification, in order of publication.
+const (
+	// DraftUnknown is used when the $schema keyword doesn't identify a version.
+	DraftUnknown Draft = iota
+	Draft04
+	Draft06
+	Draft07
+	Draft201909
+	Draft202012
+)
+
+// the meta-schema URIs, without the scheme or fragment
+var draftURIs = map[string]Draft{
+	"json-schema.org/draft-04/schema":      Draft04,
+	"json-schema.org/draft-06/schema":      Draft06,
+	"json-schema.org/draft-07/schema":      Draft07,
+	"json-schema.org/draft/2019-09/schema": Draft201909,
+	"json-schema.org/draft/2020-12/schema": Draft202012,
+}
+
+// Draft returns the version of the specification identified by the $schema keyword of the root schema.
+func (schema *Schema) Draft() Draft {
+	u, err := url.Parse(schema.GetRoot().SchemaType)
+	if err != nil {
+		return DraftUnknown
+	}
+	return draftURIs[u.Host+strings.TrimSuffix(u.Path, "/")]
+}
+
+// refHasSiblings returns true when the schema combines a $ref with its own properties. From draft 2019-09 onwards,
+// the referenced schema and the properties both apply, in the same way as an allOf. Up to draft-07, keywords
+// alongside a $ref are ignored.
+func (schema *Schema) refHasSiblings() bool {
+	return schema.Reference != "" && len(schema.Properties) > 0 && schema.Draft() >= Draft201909
+}
+
+// definitionSchemas returns the inline re-usable schemas, from both "definitions" and "$defs".
+func (schema *Schema) definitionSchemas() map[string]*Schema {
+	if len(schema.Defs) == 0 {
+		return schema.Definitions
+	}
+	if len(schema.Definitions) == 0 {
+		return schema.Defs
+	}
+	rv := make(map[string]*Schema, len(schema.Definitions)+len(schema.Defs))
+	for k, s := range schema.Definitions {
+		rv[k] = s
+	}
+	for k, s := range schema.Defs {
+		rv[k] = s
+	}
+	return rv
+}
+
+// dependentSchemaList returns the schemas which apply when a property is present, from both "dependentSchemas" and
+// the schema form of "dependencies", ordered by property name.
+func (schema *Schema) dependentSchemaList() []*Schema {
+	var rv []*Schema
+	for _, m := range []map[string]*Schema{schema.dependencySchemas(), schema.DependentSchemas} {
+		for _, k := range getOrderedSchemaKeys(m) {
+			rv = append(rv, m[k])
+		}
+	}
+	return rv
+}
+
+// dependentRequiredProperties returns the names of the properties which must be present when a property is, from
+// both "dependentRequired" and the array form of "dependencies".
+func (schema *Schema) dependentRequiredProperties() map[string][]string {
+	rv := make(map[string][]string)
+	for k, d := range schema.Dependencies {
+		if d.Schema == nil {
+			rv[k] = append(rv[k], d.Properties...)
+		}
+	}
+	for k, properties := range schema.DependentRequired {
+		rv[k] = append(rv[k], properties...)
+	}
+	return rv
+}
+
+func (schema *Schema) dependencySchemas() map[string]*Schema {
+	rv := make(map[string]*Schema)
+	for k, d := range schema.Dependencies {
+		if d.Schema != nil {
+			rv[k] = d.Schema
+		}
+	}
+	return rv
+}
+
+// Discriminator maps the value of a property to the sub-schema that the instance should be validated against.
+type Discriminator struct {
+	// PropertyName is the name of the property holding the discriminator value.
+	PropertyName string `json:"propertyName"`
+	// Mapping maps discriminator values to schema references. When a sub-schema has no mapping, the name of the
+	// definition it references is used as its discriminator value.
+	Mapping map[string]string `json:"mapping"`
+}
+
+// Dependency is a value of the "dependencies" keyword, either the names of the properties which must be present
+// when the property is, or a schema the whole instance must then be valid against.
+// http://json-schema.org/draft-07/json-schema-validation.html#rfc.section.6.5.7
+type Dependency struct {
+	Properties []string
+	Schema     *Schema
+}
+
+// UnmarshalJSON handles unmarshalling a Dependency from JSON.
+func (d *Dependency) UnmarshalJSON(data []byte) error {
+	var properties []string
+	if err := json.Unmarshal(data, &properties); err == nil {
+		d.Properties = properties
+		return nil
+	}
+	d.Schema = &Schema{}
+	return json.Unmarshal(data, d.Schema)
 }
 
 // UnmarshalJSON handles unmarshalling AdditionalProperties from JSON.
@@ -120,6 +487,30 @@ func (schema *Schema) ID() string {
 	return schema.ID06
 }
 
+// ExclusiveMaximum returns the exclusive upper bound of a numeric instance, if there is one.
+func (schema *Schema) ExclusiveMaximum() (bound float64, ok bool) {
+	return exclusiveBound(schema.ExclusiveMaximumValue, schema.Maximum)
+}
+
+// ExclusiveMinimum returns the exclusive lower bound of a numeric instance, if there is one.
+func (schema *Schema) ExclusiveMinimum() (bound float64, ok bool) {
+	return exclusiveBound(schema.ExclusiveMinimumValue, schema.Minimum)
+}
+
+// up to draft-04 the exclusive keywords are booleans which make the inclusive bound exclusive, from draft-06 onwards
+// they're numbers.
+func exclusiveBound(exclusive interface{}, inclusive *float64) (float64, bool) {
+	switch v := exclusive.(type) {
+	case bool:
+		if v && inclusive != nil {
+			return *inclusive, true
+		}
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
 // Type returns the type which is permitted or an empty string if the type field is missing.
 // The 'type' field in JSON schema also allows for a single string value or an array of strings.
 // Examples:
@@ -170,6 +561,20 @@ func (schema *Schema) MultiType() ([]string, bool) {
 	return nil, false
 }
 
+// isNullable returns true when null is permitted alongside another type, either by "type", e.g. ["string", "null"],
+// or by a oneOf or anyOf sub-schema of type null.
+func (schema *Schema) isNullable() bool {
+	if types, multiple := schema.MultiType(); multiple && contains(types, "null") {
+		return true
+	}
+	for _, subSchema := range append(append([]*Schema{}, schema.OneOf...), schema.AnyOf...) {
+		if t, multiple := subSchema.Type(); t == "null" && !multiple {
+			return true
+		}
+	}
+	return false
+}
+
 // GetRoot returns the root schema.
 func (schema *Schema) GetRoot() *Schema {
 	if schema.Parent != nil {
@@ -195,6 +600,8 @@ func ParseWithSchemaKeyRequired(schema string, uri *url.URL, schemaKeyRequired b
 	if s.ID() == "" {
 		s.ID06 = uri.String()
 	}
+	s.schemaKeyRequired = schemaKeyRequired
+	s.raw = json.RawMessage(schema)
 
 	if schemaKeyRequired && s.SchemaType == "" {
 		return s, errors.New("JSON schema must have a $schema key unless schemaKeyRequired flag is set")
@@ -223,81 +630,44 @@ func (schema *Schema) Init() {
 }
 
 func (schema *Schema) updatePathElements() {
-	if schema.IsRoot() {
-		schema.PathElement = "#"
-	}
-
-	for k, d := range schema.Definitions {
-		d.PathElement = "definitions/" + k
-		d.updatePathElements()
-	}
-
-	for k, p := range schema.Properties {
-		p.PathElement = "properties/" + k
-		p.updatePathElements()
-	}
+	Walk(VisitorFunc(func(s SubSchema) bool {
+		if s.Keyword != "" {
+			s.Schema.PathElement = s.PathElement()
+		} else if s.Schema.IsRoot() {
+			s.Schema.PathElement = "#"
+		}
+		return true
+	}), schema)
+}
 
-	if schema.AdditionalProperties != nil {
-		schema.AdditionalProperties.PathElement = "additionalProperties"
-		(*Schema)(schema.AdditionalProperties).updatePathElements()
-	}
+// parentLinker sets the Parent of the schemas it visits.
+type parentLinker struct {
+	parent *Schema
+}
 
-	if schema.Items != nil {
-		schema.Items.PathElement = "items"
-		schema.Items.updatePathElements()
+func (v parentLinker) Visit(s SubSchema) Visitor {
+	if v.parent != nil {
+		if s.isNamed() {
+			s.Schema.JSONKey = s.Key
+		}
+		s.Schema.Parent = v.parent
 	}
+	return parentLinker{parent: s.Schema}
 }
 
 func (schema *Schema) updateParentLinks() {
-	for k, d := range schema.Definitions {
-		d.JSONKey = k
-		d.Parent = schema
-		d.updateParentLinks()
-	}
-
-	for k, p := range schema.Properties {
-		p.JSONKey = k
-		p.Parent = schema
-		p.updateParentLinks()
-	}
-	if schema.AdditionalProperties != nil {
-		schema.AdditionalProperties.Parent = schema
-		(*Schema)(schema.AdditionalProperties).updateParentLinks()
-	}
-	if schema.Items != nil {
-		schema.Items.Parent = schema
-		schema.Items.updateParentLinks()
-	}
+	Walk(parentLinker{}, schema)
 }
 
-func (schema *Schema) ensureSchemaKeyword() error {
-	check := func(k string, s *Schema) error {
-		if s.SchemaType != "" {
-			return errors.New("invalid $schema keyword: " + k)
-		}
-		return s.ensureSchemaKeyword()
-	}
-	for k, d := range schema.Definitions {
-		if err := check(k, d); err != nil {
-			return err
+func (schema *Schema) ensureSchemaKeyword() (err error) {
+	Walk(VisitorFunc(func(s SubSchema) bool {
+		// the first invalid keyword is reported, rather than any which follow it
+		if err == nil && s.Keyword != "" && s.Schema.SchemaType != "" {
+			err = errors.New("invalid $schema keyword: " + s.PathElement())
 		}
-	}
-	for k, d := range schema.Properties {
-		if err := check(k, d); err != nil {
-			return err
-		}
-	}
-	if schema.AdditionalProperties != nil {
-		if err := check("additionalProperties", (*Schema)(schema.AdditionalProperties)); err != nil {
-			return err
-		}
-	}
-	if schema.Items != nil {
-		if err := check("items", schema.Items); err != nil {
-			return err
-		}
-	}
-	return nil
+		return err == nil
+	}), schema)
+	return err
 }
 
 // FixMissingTypeValue is backwards compatible, guessing the users intention when they didn't specify a type.
@@ -307,7 +677,7 @@ func (schema *Schema) FixMissingTypeValue() {
 			schema.TypeValue = "object"
 			return
 		}
-		if schema.Items != nil {
+		if schema.Items != nil || len(schema.PrefixItems) > 0 {
 			schema.TypeValue = "array"
 			return
 		}
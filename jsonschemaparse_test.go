@@ -2,6 +2,7 @@ package generate
 
 import (
 	"net/url"
+	"reflect"
 	"testing"
 )
 
@@ -165,3 +166,297 @@ func TestReturnedSchemaId(t *testing.T) {
 		}
 	}
 }
+
+func TestThatValidationKeywordsCanBeParsed(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "$comment": "for maintainers",
+        "title": "root",
+        "type": "object",
+        "minProperties": 1,
+        "maxProperties": 5,
+        "properties": {
+            "name": {
+                "type": "string",
+                "minLength": 2,
+                "maxLength": 10,
+                "pattern": "^[a-z]+$",
+                "format": "hostname",
+                "readOnly": true
+            },
+            "price": {
+                "type": "number",
+                "minimum": 0,
+                "exclusiveMinimum": true,
+                "maximum": 100,
+                "exclusiveMaximum": 200,
+                "multipleOf": 0.5
+            },
+            "tags": {
+                "type": "array",
+                "minItems": 1,
+                "maxItems": 3,
+                "uniqueItems": true,
+                "contains": { "const": "a" }
+            },
+            "kind": { "enum": [ "a", 1 ], "writeOnly": true },
+            "data": { "type": "string", "contentEncoding": "base64", "contentMediaType": "image/png" }
+        },
+        "patternProperties": {
+            "^x-": { "type": "string" }
+        },
+        "dependencies": {
+            "price": [ "name" ],
+            "tags": { "required": [ "kind" ] }
+        },
+        "propertyNames": { "maxLength": 8 },
+        "not": { "required": [ "forbidden" ] },
+        "if": { "required": [ "kind" ] },
+        "then": { "required": [ "name" ] },
+        "else": { "required": [ "tags" ] }
+    }`
+	so, err := Parse(s, &url.URL{Scheme: "file", Path: "jsonschemaparse_test.go"})
+	if err != nil {
+		t.Fatal("It was not possible to unmarshal the schema:", err)
+	}
+
+	if so.Comment != "for maintainers" {
+		t.Errorf("expected $comment to be parsed, got %q", so.Comment)
+	}
+	if *so.MinProperties != 1 || *so.MaxProperties != 5 {
+		t.Errorf("expected minProperties 1 and maxProperties 5, got %d and %d", *so.MinProperties, *so.MaxProperties)
+	}
+
+	name := so.Properties["name"]
+	if *name.MinLength != 2 || *name.MaxLength != 10 || name.Pattern != "^[a-z]+$" || name.Format != "hostname" || !name.ReadOnly {
+		t.Errorf("unexpected string keywords: %+v", name)
+	}
+
+	price := so.Properties["price"]
+	if *price.Minimum != 0 || *price.Maximum != 100 || *price.MultipleOf != 0.5 {
+		t.Errorf("unexpected numeric keywords: %+v", price)
+	}
+	if min, ok := price.ExclusiveMinimum(); !ok || min != 0 {
+		t.Errorf("expected the draft-04 exclusiveMinimum to make minimum exclusive, got %v, %v", min, ok)
+	}
+	if max, ok := price.ExclusiveMaximum(); !ok || max != 200 {
+		t.Errorf("expected the draft-06 exclusiveMaximum to be 200, got %v, %v", max, ok)
+	}
+
+	tags := so.Properties["tags"]
+	if *tags.MinItems != 1 || *tags.MaxItems != 3 || !tags.UniqueItems || tags.Contains.Const != "a" {
+		t.Errorf("unexpected array keywords: %+v", tags)
+	}
+
+	kind := so.Properties["kind"]
+	if !reflect.DeepEqual(kind.Enum, []interface{}{"a", 1.0}) || !kind.WriteOnly {
+		t.Errorf("unexpected enum keywords: %+v", kind)
+	}
+
+	data := so.Properties["data"]
+	if data.ContentEncoding != "base64" || data.ContentMediaType != "image/png" {
+		t.Errorf("unexpected content keywords: %+v", data)
+	}
+
+	if !reflect.DeepEqual(so.Dependencies["price"].Properties, []string{"name"}) {
+		t.Errorf("expected the price dependency to be a list of properties, got %+v", so.Dependencies["price"])
+	}
+	if !reflect.DeepEqual(so.Dependencies["tags"].Schema.Required, []string{"kind"}) {
+		t.Errorf("expected the tags dependency to be a schema, got %+v", so.Dependencies["tags"])
+	}
+
+	subSchemas := map[string]*Schema{
+		"patternProperties/^x-": so.PatternProperties["^x-"],
+		"dependencies/tags":     so.Dependencies["tags"].Schema,
+		"propertyNames":         so.PropertyNames,
+		"not":                   so.Not,
+		"if":                    so.If,
+		"then":                  so.Then,
+		"else":                  so.Else,
+		"contains":              tags.Contains,
+	}
+	for pathElement, sub := range subSchemas {
+		if sub == nil {
+			t.Errorf("%s: expected a sub-schema", pathElement)
+			continue
+		}
+		if sub.PathElement != pathElement {
+			t.Errorf("%s: expected the path element to be set, got %q", pathElement, sub.PathElement)
+		}
+		if sub.GetRoot() != so {
+			t.Errorf("%s: expected the parent links to lead to the root", pathElement)
+		}
+	}
+}
+
+func TestThatSchemaKeywordsAreRejectedInNewSubSchemas(t *testing.T) {
+	root := &Schema{
+		Not: &Schema{SchemaType: "http://json-schema.org/draft-07/schema#"},
+	}
+	if err := root.ensureSchemaKeyword(); err == nil || err.Error() != "invalid $schema keyword: not" {
+		t.Errorf("expected an error for the $schema keyword in the not sub-schema, got %v", err)
+	}
+
+	root = &Schema{
+		AllOf: []*Schema{
+			{SchemaType: "http://json-schema.org/draft-07/schema#"},
+			{SchemaType: "http://json-schema.org/draft-07/schema#"},
+		},
+	}
+	if err := root.ensureSchemaKeyword(); err == nil || err.Error() != "invalid $schema keyword: allOf/0" {
+		t.Errorf("expected an error for the $schema keyword in the first allOf sub-schema, got %v", err)
+	}
+}
+
+func TestThatTheDraftIsIdentifiedFromTheSchemaKeyword(t *testing.T) {
+	tests := []struct {
+		schema   string
+		expected Draft
+	}{
+		{schema: "http://json-schema.org/draft-04/schema#", expected: Draft04},
+		{schema: "http://json-schema.org/draft-07/schema", expected: Draft07},
+		{schema: "https://json-schema.org/draft/2019-09/schema", expected: Draft201909},
+		{schema: "https://json-schema.org/draft/2020-12/schema#", expected: Draft202012},
+		{schema: "http://json-schema.org/schema#", expected: DraftUnknown},
+		{schema: "", expected: DraftUnknown},
+	}
+
+	for _, test := range tests {
+		root := &Schema{SchemaType: test.schema, Properties: map[string]*Schema{"a": {}}}
+		root.Init()
+		if actual := root.Properties["a"].Draft(); actual != test.expected {
+			t.Errorf("%q: expected draft %d, got %d", test.schema, test.expected, actual)
+		}
+	}
+}
+
+func TestThatTupleItemsCanBeParsed(t *testing.T) {
+	tests := []struct {
+		name            string
+		schema          string
+		expectedItems   string
+		expectedClosed  bool
+		expectedElement string
+	}{
+		{
+			name:            "prefixItems",
+			schema:          `{"prefixItems": [{"type": "string"}, {"type": "integer"}], "items": {"type": "boolean"}}`,
+			expectedItems:   "boolean",
+			expectedElement: "prefixItems/1",
+		},
+		{
+			name:            "closed prefixItems",
+			schema:          `{"prefixItems": [{"type": "string"}, {"type": "integer"}], "items": false}`,
+			expectedClosed:  true,
+			expectedElement: "prefixItems/1",
+		},
+		{
+			name:            "items array",
+			schema:          `{"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": {"type": "boolean"}}`,
+			expectedItems:   "boolean",
+			expectedElement: "items/1",
+		},
+		{
+			name:            "closed items array",
+			schema:          `{"items": [{"type": "string"}, {"type": "integer"}], "additionalItems": false}`,
+			expectedClosed:  true,
+			expectedElement: "items/1",
+		},
+	}
+
+	for _, test := range tests {
+		s, err := ParseWithSchemaKeyRequired(test.schema, &url.URL{Scheme: "file", Path: "jsonschemaparse_test.go"}, false)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(s.PrefixItems) != 2 {
+			t.Fatalf("%s: expected 2 prefix items, got %d", test.name, len(s.PrefixItems))
+		}
+		if s.PrefixItems[1].PathElement != test.expectedElement {
+			t.Errorf("%s: expected the path element %q, got %q", test.name, test.expectedElement, s.PrefixItems[1].PathElement)
+		}
+		if closed := s.ItemsBool != nil && !*s.ItemsBool; closed != test.expectedClosed {
+			t.Errorf("%s: expected closed to be %v", test.name, test.expectedClosed)
+		}
+		if test.expectedItems != "" && (s.Items == nil || s.Items.TypeValue != test.expectedItems) {
+			t.Errorf("%s: expected the remaining items to be %s, got %+v", test.name, test.expectedItems, s.Items)
+		}
+	}
+}
+
+func TestThatAnchorsAndDefsCanBeReferenced(t *testing.T) {
+	s := `{
+        "$schema": "https://json-schema.org/draft/2020-12/schema",
+        "$id": "http://example.com/root.json",
+        "$defs": {
+            "address": { "$anchor": "addr", "type": "object" }
+        }
+    }`
+	root, err := Parse(s, &url.URL{Scheme: "file", Path: "jsonschemaparse_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := NewRefResolver([]*Schema{root})
+	if err := resolver.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []string{"#addr", "#/$defs/address"} {
+		actual, err := resolver.GetSchemaByReference(&Schema{Reference: ref, Parent: root})
+		if err != nil {
+			t.Errorf("%s: %v", ref, err)
+			continue
+		}
+		if actual != root.Defs["address"] {
+			t.Errorf("%s: resolved to the wrong schema", ref)
+		}
+	}
+}
+
+func TestThatExtensionsCanBeParsed(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "x-go-type": "github.com/shopspring/decimal.Decimal",
+        "x-tags": ["a", "b"],
+        "type": "number"
+    }`
+	so, err := Parse(s, &url.URL{Scheme: "file", Path: "jsonschemaparse_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"x-go-type": "github.com/shopspring/decimal.Decimal",
+		"x-tags":    []interface{}{"a", "b"},
+	}
+	if !reflect.DeepEqual(so.Extensions, expected) {
+		t.Errorf("expected %v, got %v", expected, so.Extensions)
+	}
+}
+
+func TestThatTheOrderOfPropertiesAndDefinitionsIsKept(t *testing.T) {
+	s := `{
+        "$schema": "https://json-schema.org/draft/2020-12/schema",
+        "properties": {
+            "z": { "type": "string" },
+            "a": { "type": "string" },
+            "m": { "type": "string" }
+        },
+        "definitions": {
+            "y": { "type": "string" },
+            "b": { "type": "string" }
+        },
+        "$defs": {
+            "x": { "type": "string" },
+            "c": { "type": "string" }
+        }
+    }`
+	so, err := Parse(s, &url.URL{Scheme: "file", Path: "jsonschemaparse_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"z", "a", "m"}; !reflect.DeepEqual(so.propertyKeys(), expected) {
+		t.Errorf("expected properties %v, got %v", expected, so.propertyKeys())
+	}
+	if expected := []string{"y", "b", "x", "c"}; !reflect.DeepEqual(so.definitionKeys(), expected) {
+		t.Errorf("expected definitions %v, got %v", expected, so.definitionKeys())
+	}
+}
@@ -0,0 +1,193 @@
+package generate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Loader reads the documents referenced by schemas, so that references to documents which weren't passed to the
+// generator can be resolved.
+type Loader interface {
+	// Load returns the content of the document at the URI, which has no fragment.
+	Load(uri *url.URL) ([]byte, error)
+}
+
+// FileLoader reads documents with file URIs from disk.
+type FileLoader struct{}
+
+// Load reads the file at the path of the URI.
+func (FileLoader) Load(uri *url.URL) ([]byte, error) {
+	if uri.Scheme != "file" {
+		return nil, errors.New("only file URIs can be read from disk")
+	}
+	return ioutil.ReadFile(filepath.FromSlash(uri.Path))
+}
+
+// defaultClient stops a server which doesn't respond from hanging the generator.
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// HTTPLoader fetches documents with http and https URIs, optionally storing them in a cache directory so that they
+// can be read from there, e.g. in CI, without fetching them again.
+type HTTPLoader struct {
+	// Client is used to fetch documents, or a client which gives up after 30 seconds when it's nil.
+	Client *http.Client
+	// CacheDir stores the fetched documents, in a directory for each host. Documents which are in the cache aren't
+	// fetched again.
+	CacheDir string
+	// Offline only reads documents from the CacheDir, rather than fetching those which are missing.
+	Offline bool
+	// Lockfile, when set, holds the checksum of each document. Documents whose checksum is different are rejected,
+	// and the checksums of new documents are added.
+	Lockfile *Lockfile
+}
+
+// Load reads the document from the cache, or fetches it.
+func (l *HTTPLoader) Load(uri *url.URL) ([]byte, error) {
+	if uri.Scheme != "http" && uri.Scheme != "https" {
+		return nil, errors.New("only http and https URIs can be fetched")
+	}
+	b, err := l.read(uri)
+	if err != nil {
+		return nil, err
+	}
+	if l.Lockfile != nil {
+		if err := l.Lockfile.Verify(uri.String(), b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (l *HTTPLoader) read(uri *url.URL) ([]byte, error) {
+	cachePath := ""
+	if l.CacheDir != "" {
+		cachePath = l.cachePath(uri)
+		b, err := ioutil.ReadFile(cachePath)
+		if err == nil {
+			return b, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if l.Offline {
+		if l.CacheDir == "" {
+			return nil, errors.New("documents can't be fetched when offline")
+		}
+		return nil, fmt.Errorf("the document isn't in the cache directory %s, and can't be fetched when offline", l.CacheDir)
+	}
+	b, err := l.fetch(uri)
+	if err != nil {
+		return nil, err
+	}
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(cachePath, b, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (l *HTTPLoader) fetch(uri *url.URL) ([]byte, error) {
+	client := l.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Get(uri.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// cachePath returns the path of the document within the CacheDir, e.g. "example.com/schemas/address.json" for
+// "https://example.com/schemas/address.json".
+func (l *HTTPLoader) cachePath(uri *url.URL) string {
+	// cleaning the rooted path stops it from leaving the cache directory
+	p := path.Clean("/" + uri.Path)
+	if p == "/" {
+		p = "/index.json"
+	}
+	if uri.RawQuery != "" {
+		p += url.PathEscape("?" + uri.RawQuery)
+	}
+	return filepath.Join(l.CacheDir, url.PathEscape(uri.Host), filepath.FromSlash(p))
+}
+
+// Lockfile holds the checksums of remote documents, so that changes to them are noticed.
+type Lockfile struct {
+	// Checksums are keyed by the URI of the document, e.g. "sha256:2c26b46b...".
+	Checksums map[string]string
+	changed   bool
+}
+
+// ReadLockfile reads a JSON file containing an object of checksums keyed by URI. A missing file is read as an empty
+// Lockfile.
+func ReadLockfile(file string) (*Lockfile, error) {
+	l := &Lockfile{Checksums: make(map[string]string)}
+	b, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to read the lockfile with error " + err.Error())
+	}
+	if err := json.Unmarshal(b, &l.Checksums); err != nil {
+		return nil, errors.New("failed to parse the lockfile " + file + " with error " + err.Error())
+	}
+	if l.Checksums == nil {
+		l.Checksums = make(map[string]string)
+	}
+	return l, nil
+}
+
+// Verify returns an error if the checksum of the content is different to the one held for the URI, or adds the
+// checksum if there isn't one.
+func (l *Lockfile) Verify(uri string, content []byte) error {
+	sum := sha256.Sum256(content)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	expected, ok := l.Checksums[uri]
+	if !ok {
+		if l.Checksums == nil {
+			l.Checksums = make(map[string]string)
+		}
+		l.Checksums[uri] = checksum
+		l.changed = true
+		return nil
+	}
+	if expected != checksum {
+		return fmt.Errorf("the checksum of the document is %s, but the lockfile holds %s", checksum, expected)
+	}
+	return nil
+}
+
+// Changed returns true when checksums have been added since the Lockfile was read.
+func (l *Lockfile) Changed() bool {
+	return l.changed
+}
+
+// Write writes the checksums to a JSON file.
+func (l *Lockfile) Write(file string) error {
+	b, err := json.MarshalIndent(l.Checksums, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(b, '\n'), 0644)
+}
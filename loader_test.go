@@ -0,0 +1,139 @@
+package generate
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newSchemaServer(documents map[string]string) (server *httptest.Server, requests *int) {
+	requests = new(int)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		doc, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(doc))
+	}))
+	return server, requests
+}
+
+func TestThatHTTPLoaderCachesDocuments(t *testing.T) {
+	server, requests := newSchemaServer(map[string]string{"/schemas/a.json": `{"type": "string"}`})
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	uri, _ := url.Parse(server.URL + "/schemas/a.json")
+	loader := &HTTPLoader{CacheDir: dir}
+	for i := 0; i < 2; i++ {
+		b, err := loader.Load(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != `{"type": "string"}` {
+			t.Errorf("unexpected document %s", b)
+		}
+	}
+	if *requests != 1 {
+		t.Errorf("expected the document to be fetched once, but it was fetched %d times", *requests)
+	}
+	if _, err := os.Stat(filepath.Join(dir, uri.Host, "schemas", "a.json")); err != nil {
+		t.Errorf("expected the document to be cached: %v", err)
+	}
+
+	offline := &HTTPLoader{CacheDir: dir, Offline: true}
+	if _, err := offline.Load(uri); err != nil {
+		t.Errorf("expected the cached document to be read when offline: %v", err)
+	}
+	missing, _ := url.Parse(server.URL + "/schemas/b.json")
+	if _, err := offline.Load(missing); err == nil || !strings.Contains(err.Error(), "can't be fetched when offline") {
+		t.Errorf("expected an error for a document which isn't cached, got %v", err)
+	}
+	if *requests != 1 {
+		t.Errorf("expected no documents to be fetched when offline, but %d were", *requests-1)
+	}
+
+	if _, err := loader.Load(missing); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected an error for a document which isn't found, got %v", err)
+	}
+}
+
+func TestThatTheLockfileRejectsChangedDocuments(t *testing.T) {
+	documents := map[string]string{"/a.json": `{"type": "string"}`}
+	server, _ := newSchemaServer(documents)
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "generate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schemas.lock")
+
+	lockfile, err := ReadLockfile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, _ := url.Parse(server.URL + "/a.json")
+	if _, err := (&HTTPLoader{Lockfile: lockfile}).Load(uri); err != nil {
+		t.Fatal(err)
+	}
+	if !lockfile.Changed() {
+		t.Error("expected the checksum to be added")
+	}
+	if err := lockfile.Write(file); err != nil {
+		t.Fatal(err)
+	}
+
+	documents["/a.json"] = `{"type": "integer"}`
+	lockfile, err = ReadLockfile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&HTTPLoader{Lockfile: lockfile}).Load(uri)
+	expected := "the checksum of the document is sha256:"
+	if err == nil || !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("expected an error starting %q, got %v", expected, err)
+	}
+	if lockfile.Changed() {
+		t.Error("expected the lockfile to be unchanged")
+	}
+}
+
+func TestThatRemoteReferencesAreResolvedWithALoader(t *testing.T) {
+	server, _ := newSchemaServer(map[string]string{
+		"/address.json": `{ "definitions": { "address": { "title": "Address", "properties": { "street": { "type": "string" } } } } }`,
+	})
+	defer server.Close()
+
+	root, err := ParseWithSchemaKeyRequired(`{ "title": "Order", "properties": {
+		"address": { "$ref": "`+server.URL+`/address.json#/definitions/address" } } }`,
+		&url.URL{Scheme: "file", Path: "/order.json"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := New(root)
+	if err := g.CreateTypes(); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected remote references not to be resolved by default, got %v", err)
+	}
+
+	g = New(root)
+	g.Loaders = map[string]Loader{"http": &HTTPLoader{}}
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Structs["Order"].Fields["Address"].Type != "*Address" || g.Structs["Address"].Fields["Street"].Type != "string" {
+		t.Errorf("unexpected types: %+v", g.Structs)
+	}
+}
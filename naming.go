@@ -0,0 +1,102 @@
+package generate
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CommonInitialisms are the initialisms that golint expects to be written in upper case, e.g. "ID" and "URL".
+var CommonInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON", "LHS",
+	"QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID", "URI",
+	"URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// getGolangName strips invalid characters out of golang struct or field names.
+func getGolangName(s string) string {
+	return toGolangName(s, nil)
+}
+
+// toGolangName strips invalid characters out of golang struct or field names, and writes any of the initialisms that
+// it contains in upper case.
+func toGolangName(s string, initialisms map[string]bool) string {
+	buf := bytes.NewBuffer([]byte{})
+	for i, v := range splitOnAll(s, isNotAGoNameCharacter) {
+		if i == 0 && strings.IndexAny(v, "0123456789") == 0 {
+			// Go types are not allowed to start with a number, lets prefix with an underscore.
+			buf.WriteRune('_')
+		}
+		if len(initialisms) == 0 {
+			buf.WriteString(capitaliseFirstLetter(v))
+			continue
+		}
+		for _, word := range splitWords(v) {
+			if upper := strings.ToUpper(word); initialisms[upper] {
+				buf.WriteString(upper)
+			} else {
+				buf.WriteString(capitaliseFirstLetter(word))
+			}
+		}
+	}
+	return buf.String()
+}
+
+func splitOnAll(s string, shouldSplit func(r rune) bool) []string {
+	rv := []string{}
+	buf := bytes.NewBuffer([]byte{})
+	for _, c := range s {
+		if shouldSplit(c) {
+			rv = append(rv, buf.String())
+			buf.Reset()
+		} else {
+			buf.WriteRune(c)
+		}
+	}
+	if buf.Len() > 0 {
+		rv = append(rv, buf.String())
+	}
+	return rv
+}
+
+func isNotAGoNameCharacter(r rune) bool {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return false
+	}
+	return true
+}
+
+func capitaliseFirstLetter(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// getGolangName converts s to a golang name, writing the Initialisms in upper case.
+func (g *Generator) getGolangName(s string) string {
+	if g.initialisms == nil {
+		g.initialisms = make(map[string]bool, len(g.Initialisms))
+		for _, initialism := range g.Initialisms {
+			g.initialisms[strings.ToUpper(initialism)] = true
+		}
+	}
+	return toGolangName(s, g.initialisms)
+}
+
+// splitWords splits a camel case name into words in the same way as golint, i.e. after each lower case letter which
+// isn't followed by another, e.g. "userId" into "user" and "Id".
+func splitWords(s string) []string {
+	runes := []rune(s)
+	words := []string{}
+	start := 0
+	for i := 0; i+1 < len(runes); i++ {
+		if unicode.IsLower(runes[i]) && !unicode.IsLower(runes[i+1]) {
+			words = append(words, string(runes[start:i+1]))
+			start = i + 1
+		}
+	}
+	return append(words, string(runes[start:]))
+}
@@ -0,0 +1,60 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThatInitialismsAreWrittenInUpperCase(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "id", expected: "ID"},
+		{input: "userId", expected: "UserID"},
+		{input: "user_id", expected: "UserID"},
+		{input: "httpStatus", expected: "HTTPStatus"},
+		{input: "HttpStatus", expected: "HTTPStatus"},
+		{input: "imageUrl", expected: "ImageURL"},
+		{input: "identity", expected: "Identity"},
+		{input: "skuCode", expected: "SKUCode"},
+		{input: "123id", expected: "_123id"},
+	}
+
+	g := New()
+	g.Initialisms = append(CommonInitialisms, "sku")
+	for _, test := range tests {
+		if actual := g.getGolangName(test.input); actual != test.expected {
+			t.Errorf("for input %q expected %q but got %q", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestThatInitialismsAreOnlyUsedWhenSet(t *testing.T) {
+	if actual := New().getGolangName("userId"); actual != "UserId" {
+		t.Errorf("expected UserId, got %s", actual)
+	}
+}
+
+func TestThatNamesAreSplitIntoWords(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "userId", expected: []string{"user", "Id"}},
+		{input: "HTTPServer", expected: []string{"HTTPServer"}},
+		{input: "ipv4Address", expected: []string{"ipv", "4Address"}},
+		{input: "", expected: []string{""}},
+	}
+	for _, test := range tests {
+		if actual := splitWords(test.input); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("for input %q expected %v but got %v", test.input, test.expected, actual)
+		}
+	}
+}
+
+func TestThatNonASCIILettersAreCapitalised(t *testing.T) {
+	if actual := getGolangName("élan"); actual != "Élan" {
+		t.Errorf("expected Élan, got %s", actual)
+	}
+}
@@ -3,11 +3,18 @@ package generate
 import (
 	"bytes"
 	"fmt"
+	"go/format"
+	"go/scanner"
+	"go/token"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 )
 
+// typesImport is the package containing the types used by the generated code, e.g. types.ValidationErrors.
+const typesImport = "github.com/a-h/generate/types"
+
 func getOrderedFieldNames(m map[string]Field) []string {
 	keys := make([]string, len(m))
 	idx := 0
@@ -19,6 +26,14 @@ func getOrderedFieldNames(m map[string]Field) []string {
 	return keys
 }
 
+// fieldNames returns the keys of the struct's fields in the order they should be output.
+func (s Struct) fieldNames() []string {
+	if len(s.FieldOrder) == len(s.Fields) {
+		return s.FieldOrder
+	}
+	return getOrderedFieldNames(s.Fields)
+}
+
 func getOrderedStructNames(m map[string]Struct) []string {
 	keys := make([]string, len(m))
 	idx := 0
@@ -30,10 +45,57 @@ func getOrderedStructNames(m map[string]Struct) []string {
 	return keys
 }
 
-// Output generates code and writes to w.
-func Output(w io.Writer, g *Generator, pkg string) {
+func getOrderedEnumNames(m map[string]Enum) []string {
+	keys := make([]string, len(m))
+	idx := 0
+	for k := range m {
+		keys[idx] = k
+		idx++
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func getOrderedUnionNames(m map[string]Union) []string {
+	keys := make([]string, len(m))
+	idx := 0
+	for k := range m {
+		keys[idx] = k
+		idx++
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func getOrderedTupleNames(m map[string]Tuple) []string {
+	keys := make([]string, len(m))
+	idx := 0
+	for k := range m {
+		keys[idx] = k
+		idx++
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Output generates code, formats it in the same way as gofmt, and writes it to w.
+func Output(w io.Writer, g *Generator, pkg string) error {
+	src, err := formatSource(generateSource(g, pkg))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// generateSource returns the unformatted code for the types.
+func generateSource(g *Generator, pkg string) []byte {
+	w := new(bytes.Buffer)
 	structs := g.Structs
 	aliases := g.Aliases
+	unions := g.Unions
+	enums := g.Enums
+	tuples := g.Tuples
 
 	fmt.Fprintln(w, "// Code generated by schema-generate. DO NOT EDIT.")
 	fmt.Fprintln(w)
@@ -43,19 +105,87 @@ func Output(w io.Writer, g *Generator, pkg string) {
 	// write list of imports into main output stream, followed by the code
 	codeBuf := new(bytes.Buffer)
 	imports := make(map[string]bool)
+	for k := range g.imports {
+		imports[k] = true
+	}
 
+	defaults := newDefaultsEmitter(g, imports)
 	for _, k := range getOrderedStructNames(structs) {
 		s := structs[k]
+		defaults.emitConstructor(codeBuf, s)
 		if s.GenerateCode {
 			emitMarshalCode(codeBuf, s, imports)
-			emitUnmarshalCode(codeBuf, s, imports)
+			if g.ApplyDefaults {
+				emitUnmarshalCode(codeBuf, s, g.schemaPath(s.schema), g.GenerateValidation, imports, defaults)
+			} else {
+				emitUnmarshalCode(codeBuf, s, g.schemaPath(s.schema), g.GenerateValidation, imports, nil)
+			}
+		}
+	}
+
+	for _, k := range getOrderedUnionNames(unions) {
+		emitUnionCode(codeBuf, unions[k], g.schemaPath(unions[k].schema), g.GenerateValidation, imports)
+	}
+
+	for _, k := range getOrderedTupleNames(tuples) {
+		emitTupleCode(codeBuf, tuples[k], g.schemaPath(tuples[k].schema), g.GenerateValidation, imports)
+	}
+
+	for _, k := range getOrderedEnumNames(enums) {
+		emitEnumCode(codeBuf, enums[k], g.schemaPath(enums[k].schema), g.GenerateValidation, imports)
+	}
+
+	if g.GenerateValidation {
+		v := newValidationEmitter(g, imports)
+		for _, k := range getOrderedFieldNames(aliases) {
+			if canHaveMethods(aliases[k].Type) {
+				v.emitAlias(codeBuf, aliases[k])
+			}
+		}
+		for _, k := range getOrderedEnumNames(enums) {
+			v.emitEnum(codeBuf, enums[k])
+		}
+		for _, k := range getOrderedStructNames(structs) {
+			v.emitStruct(codeBuf, structs[k])
+		}
+		for _, k := range getOrderedUnionNames(unions) {
+			v.emitUnion(codeBuf, unions[k])
 		}
+		for _, k := range getOrderedTupleNames(tuples) {
+			v.emitTuple(codeBuf, tuples[k])
+		}
+		v.emitPatterns(codeBuf)
 	}
 
 	if len(imports) > 0 {
 		fmt.Fprintf(w, "\nimport (\n")
+		// standard library packages first, followed by a blank line and any others
+		var std, other []string
 		for k := range imports {
-			fmt.Fprintf(w, "    \"%s\"\n", k)
+			if strings.Contains(strings.Split(k, "/")[0], ".") {
+				other = append(other, k)
+			} else {
+				std = append(std, k)
+			}
+		}
+		sort.Strings(std)
+		sort.Strings(other)
+		writeImport := func(k string) {
+			// packages which would clash with another are imported under a different name
+			if name, ok := g.importNames[k]; ok {
+				fmt.Fprintf(w, "    %s %q\n", name, k)
+				return
+			}
+			fmt.Fprintf(w, "    %q\n", k)
+		}
+		for _, k := range std {
+			writeImport(k)
+		}
+		if len(std) > 0 && len(other) > 0 {
+			fmt.Fprintln(w)
+		}
+		for _, k := range other {
+			writeImport(k)
 		}
 		fmt.Fprintf(w, ")\n")
 	}
@@ -68,6 +198,25 @@ func Output(w io.Writer, g *Generator, pkg string) {
 		fmt.Fprintf(w, "type %s %s\n", a.Name, a.Type)
 	}
 
+	for _, k := range getOrderedEnumNames(enums) {
+		e := enums[k]
+
+		fmt.Fprintln(w, "")
+		outputNameAndDescriptionComment(e.Name, e.Description, w)
+		if e.Type == "" {
+			fmt.Fprintf(w, "// The value is held as its JSON encoding.\n")
+			fmt.Fprintf(w, "type %s string\n", e.Name)
+		} else {
+			fmt.Fprintf(w, "type %s %s\n", e.Name, e.Type)
+		}
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "const (")
+		for _, v := range e.Values {
+			fmt.Fprintf(w, "  %s %s = %s\n", v.Name, e.Name, v.Value)
+		}
+		fmt.Fprintln(w, ")")
+	}
+
 	for _, k := range getOrderedStructNames(structs) {
 		s := structs[k]
 
@@ -75,7 +224,7 @@ func Output(w io.Writer, g *Generator, pkg string) {
 		outputNameAndDescriptionComment(s.Name, s.Description, w)
 		fmt.Fprintf(w, "type %s struct {\n", s.Name)
 
-		for _, fieldKey := range getOrderedFieldNames(s.Fields) {
+		for _, fieldKey := range s.fieldNames() {
 			f := s.Fields[fieldKey]
 
 			// Only apply omitempty if the field is not required.
@@ -94,8 +243,85 @@ func Output(w io.Writer, g *Generator, pkg string) {
 		fmt.Fprintln(w, "}")
 	}
 
+	for _, k := range getOrderedUnionNames(unions) {
+		u := unions[k]
+
+		fmt.Fprintln(w, "")
+		outputNameAndDescriptionComment(u.Name, u.Description, w)
+		fmt.Fprintf(w, "type %s struct {\n", u.Name)
+		fmt.Fprintf(w, "  Value %s\n", u.Interface)
+		fmt.Fprintln(w, "}")
+
+		variants := make([]string, len(u.Variants))
+		for i, v := range u.Variants {
+			variants[i] = v.Type
+		}
+		fmt.Fprintln(w, "")
+		fmt.Fprintf(w, "// %s is implemented by the types that a %s can hold: %s.\n", u.Interface, u.Name, strings.Join(variants, ", "))
+		fmt.Fprintf(w, "type %s interface {\n", u.Interface)
+		fmt.Fprintf(w, "  is%s()\n", u.Name)
+		fmt.Fprintln(w, "}")
+	}
+
+	for _, k := range getOrderedTupleNames(tuples) {
+		t := tuples[k]
+
+		fmt.Fprintln(w, "")
+		outputNameAndDescriptionComment(t.Name, t.Description, w)
+		fmt.Fprintf(w, "type %s struct {\n", t.Name)
+		for _, f := range t.Items {
+			if f.Description != "" {
+				outputFieldDescriptionComment(f.Description, w)
+			}
+			fmt.Fprintf(w, "  %s %s\n", f.Name, f.Type)
+		}
+		if t.RestType != "" {
+			outputFieldDescriptionComment(fmt.Sprintf("The items after the first %d.", len(t.Items)), w)
+			fmt.Fprintf(w, "  Rest []%s\n", t.RestType)
+		}
+		fmt.Fprintln(w, "}")
+	}
+
 	// write code after structs for clarity
 	w.Write(codeBuf.Bytes())
+	return w.Bytes()
+}
+
+// formatSource formats the generated code. If it isn't valid, the error shows the code around the problem.
+func formatSource(src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err == nil {
+		return formatted, nil
+	}
+	line := 0
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		line = list[0].Pos.Line
+	}
+	return nil, fmt.Errorf("the generated code is not valid Go: %v\n%s", err, snippet(src, line, 3))
+}
+
+// snippet returns the lines of src around the line, numbered, and with the line itself marked.
+func snippet(src []byte, line, context int) string {
+	lines := strings.Split(string(src), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	from, to := line-context, line+context
+	if from < 1 {
+		from = 1
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+	buf := new(bytes.Buffer)
+	for i := from; i <= to; i++ {
+		marker := " "
+		if i == line {
+			marker = ">"
+		}
+		fmt.Fprintf(buf, "%s %4d | %s\n", marker, i, lines[i-1])
+	}
+	return buf.String()
 }
 
 func emitMarshalCode(w io.Writer, s Struct, imports map[string]bool) {
@@ -110,7 +336,7 @@ func (strct *%s) MarshalJSON() ([]byte, error) {
 	if len(s.Fields) > 0 {
 		fmt.Fprintf(w, "    comma := false\n")
 		// Marshal all the defined fields
-		for _, fieldKey := range getOrderedFieldNames(s.Fields) {
+		for _, fieldKey := range s.fieldNames() {
 			f := s.Fields[fieldKey]
 			if f.JSONName == "-" {
 				continue
@@ -118,7 +344,7 @@ func (strct *%s) MarshalJSON() ([]byte, error) {
 			if f.Required {
 				fmt.Fprintf(w, "    // \"%s\" field is required\n", f.Name)
 				// currently only objects are supported
-				if strings.HasPrefix(f.Type, "*") {
+				if strings.HasPrefix(f.Type, "*") && (f.schema == nil || !f.schema.isNullable()) {
 					imports["errors"] = true
 					fmt.Fprintf(w, `    if strct.%s == nil {
         return nil, errors.New("%s is a required field")
@@ -129,7 +355,7 @@ func (strct *%s) MarshalJSON() ([]byte, error) {
 				}
 			}
 
-			fmt.Fprintf(w,
+			field := fmt.Sprintf(
 				`    // Marshal the "%[1]s" field
     if comma { 
         buf.WriteString(",") 
@@ -142,6 +368,14 @@ func (strct *%s) MarshalJSON() ([]byte, error) {
 	}
 	comma = true
 `, f.JSONName, f.Name)
+			// missing optional values, i.e. nil pointers, slices and maps, are omitted, rather than written as null,
+			// unless null is permitted
+			if !f.Required && isOptionalType(f.Type) {
+				field = fmt.Sprintf("    if !strct.%s.IsZero() {\n%s    }\n", f.Name, indent([]byte(field)))
+			} else if !f.Required && f.Type != "interface{}" && isNillable(f.Type) && (f.schema == nil || !f.schema.isNullable()) {
+				field = fmt.Sprintf("    if strct.%s != nil {\n%s    }\n", f.Name, indent([]byte(field)))
+			}
+			io.WriteString(w, field)
 		}
 	}
 	if s.AdditionalType != "" {
@@ -178,17 +412,24 @@ func (strct *%s) MarshalJSON() ([]byte, error) {
 `)
 }
 
-func emitUnmarshalCode(w io.Writer, s Struct, imports map[string]bool) {
+// emitUnmarshalCode writes the UnmarshalJSON method of the struct. When validate is true, the method collects every
+// error in a types.ValidationErrors, otherwise it returns the first. When defaults isn't nil, the method sets missing
+// fields to their default values.
+func emitUnmarshalCode(w io.Writer, s Struct, schemaPath string, validate bool, imports map[string]bool, defaults *defaultsEmitter) {
 	imports["encoding/json"] = true
 	// unmarshal code
 	fmt.Fprintf(w, `
 func (strct *%s) UnmarshalJSON(b []byte) error {
 `, s.Name)
+	if validate {
+		imports[typesImport] = true
+		fmt.Fprintf(w, "    var errs types.ValidationErrors\n")
+	}
 	// setup required bools
-	for _, fieldKey := range getOrderedFieldNames(s.Fields) {
+	for _, fieldKey := range s.fieldNames() {
 		f := s.Fields[fieldKey]
 		if f.Required {
-			fmt.Fprintf(w, "    %sReceived := false\n", f.JSONName)
+			fmt.Fprintf(w, "    %sReceived := false\n", f.Name)
 		}
 	}
 	// setup initial unmarshal
@@ -209,63 +450,342 @@ func (strct *%s) UnmarshalJSON(b []byte) error {
         switch k {
 `, needVal)
 	// handle defined properties
-	for _, fieldKey := range getOrderedFieldNames(s.Fields) {
+	for _, fieldKey := range s.fieldNames() {
 		f := s.Fields[fieldKey]
 		if f.JSONName == "-" {
 			continue
 		}
-		fmt.Fprintf(w, `        case "%s":
+		if validate {
+			fmt.Fprintf(w, `        case "%s":
+            errs.Merge(%q, types.Unmarshal([]byte(v), &strct.%s))
+`, f.JSONName, jsonPointer(f.JSONName), f.Name)
+		} else {
+			fmt.Fprintf(w, `        case "%s":
             if err := json.Unmarshal([]byte(v), &strct.%s); err != nil {
                 return err
              }
 `, f.JSONName, f.Name)
+		}
 		if f.Required {
-			fmt.Fprintf(w, "            %sReceived = true\n", f.JSONName)
+			fmt.Fprintf(w, "            %sReceived = true\n", f.Name)
 		}
 	}
 
 	// handle additional property
 	if s.AdditionalType != "" {
-		if s.AdditionalType == "false" {
+		if s.AdditionalType == "false" && validate {
 			// all unknown properties are not allowed
+			fmt.Fprintf(w, `        default:
+            errs.Add(types.JoinPointer("", k), %q, "additionalProperties", "additional property not allowed")
+`, schemaPath+"/additionalProperties")
+		} else if s.AdditionalType == "false" {
 			imports["fmt"] = true
 			fmt.Fprintf(w, `        default:
             return fmt.Errorf("additional property not allowed: \"" + k + "\"")
 `)
 		} else {
+			unmarshal := `if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
+                return err // invalid additionalProperty
+            }`
+			if validate {
+				unmarshal = `if err := types.Unmarshal([]byte(v), &additionalValue); err != nil {
+                errs.Merge(types.JoinPointer("", k), err) // invalid additionalProperty
+                continue
+            }`
+			}
 			fmt.Fprintf(w, `        default:
             // an additional "%s" value
             var additionalValue %s
-            if err := json.Unmarshal([]byte(v), &additionalValue); err != nil {
-                return err // invalid additionalProperty
-            }
+            %s
             if strct.AdditionalProperties == nil {
                 strct.AdditionalProperties = make(map[string]%s, 0)
             }
             strct.AdditionalProperties[k]= additionalValue
-`, s.AdditionalType, s.AdditionalType, s.AdditionalType)
+`, s.AdditionalType, s.AdditionalType, unmarshal, s.AdditionalType)
 		}
 	}
 	fmt.Fprintf(w, "        }\n") // switch
 	fmt.Fprintf(w, "    }\n")     // for
 
+	if defaults != nil {
+		defaults.emitMissing(w, s, validate)
+	}
+
 	// check all Required fields were received
-	for _, fieldKey := range getOrderedFieldNames(s.Fields) {
+	for _, fieldKey := range s.fieldNames() {
 		f := s.Fields[fieldKey]
-		if f.Required {
+		if !f.Required {
+			continue
+		}
+		fmt.Fprintf(w, "    // check if %s (a required property) was received\n    if !%sReceived {\n", f.JSONName, f.Name)
+		if validate {
+			fmt.Fprintf(w, "        errs.Add(\"\", %q, \"required\", %q)\n", schemaPath+"/required", requiredMessage(f.JSONName))
+		} else {
 			imports["errors"] = true
-			fmt.Fprintf(w, `    // check if %s (a required property) was received
-    if !%sReceived {
-        return errors.New("\"%s\" is required but was not present")
-    }
-`, f.JSONName, f.JSONName, f.JSONName)
+			fmt.Fprintf(w, "        return errors.New(%q)\n", requiredMessage(f.JSONName))
 		}
+		fmt.Fprintf(w, "    }\n")
 	}
 
-	fmt.Fprintf(w, "    return nil\n")
+	if validate {
+		fmt.Fprintf(w, "    return errs.Err()\n")
+	} else {
+		fmt.Fprintf(w, "    return nil\n")
+	}
 	fmt.Fprintf(w, "}\n") // UnmarshalJSON
 }
 
+func emitUnionCode(w io.Writer, u Union, schemaPath string, validate bool, imports map[string]bool) {
+	imports["encoding/json"] = true
+	keyword := "oneOf"
+	if u.schema != nil && len(u.schema.OneOf) == 0 {
+		keyword = "anyOf"
+	}
+	fmt.Fprintln(w)
+	for _, v := range u.Variants {
+		fmt.Fprintf(w, "func (%s) is%s() {}\n", v.Type, u.Name)
+	}
+
+	fmt.Fprintf(w, `
+func (strct *%s) MarshalJSON() ([]byte, error) {
+    return json.Marshal(strct.Value)
+}
+`, u.Name)
+
+	fmt.Fprintf(w, `
+func (strct *%s) UnmarshalJSON(b []byte) error {
+`, u.Name)
+	if u.Discriminator != "" {
+		imports["fmt"] = true
+		invalid := "err"
+		if validate {
+			invalid = validationError(imports, jsonPointer(u.Discriminator), schemaPath+"/"+keyword, keyword, "err.Error()")
+		}
+		fmt.Fprintf(w, `    var jsonMap map[string]json.RawMessage
+    if err := json.Unmarshal(b, &jsonMap); err != nil {
+        return err
+    }
+    raw, ok := jsonMap[%[1]q]
+    if !ok {
+        return %[2]s
+    }
+    var discriminator string
+    if err := json.Unmarshal(raw, &discriminator); err != nil {
+        return %[3]s
+    }
+    switch discriminator {
+`, u.Discriminator, schemaError(validate, imports, "", schemaPath+"/"+keyword, keyword, strconv.Quote(requiredMessage(u.Discriminator))), invalid)
+		for _, v := range u.Variants {
+			fmt.Fprintf(w, `    case %q:
+        var v %s
+        if err := json.Unmarshal(b, &v); err != nil {
+            return err
+        }
+        strct.Value = %s
+`, v.DiscriminatorValue, strings.TrimPrefix(v.Type, "*"), variantValue(v))
+		}
+		fmt.Fprintf(w, `    default:
+        return %s
+    }
+    return nil
+}
+`, schemaError(validate, imports, jsonPointer(u.Discriminator), schemaPath+"/"+keyword, keyword,
+			`fmt.Sprintf("%q is not one of the permitted values", discriminator)`))
+		return
+	}
+
+	// without a discriminator, use the first type that the JSON can be unmarshalled into without unknown fields
+	imports["bytes"] = true
+	for _, v := range u.Variants {
+		fmt.Fprintf(w, `    {
+        var v %s
+        dec := json.NewDecoder(bytes.NewReader(b))
+        dec.DisallowUnknownFields()
+        if err := dec.Decode(&v); err == nil {
+            strct.Value = %s
+            return nil
+        }
+    }
+`, strings.TrimPrefix(v.Type, "*"), variantValue(v))
+	}
+	fmt.Fprintf(w, "    return %s\n}\n", schemaError(validate, imports, "", schemaPath+"/"+keyword, keyword,
+		strconv.Quote("the value does not match any of the types that a "+u.Name+" can hold")))
+}
+
+func emitEnumCode(w io.Writer, e Enum, schemaPath string, validate bool, imports map[string]bool) {
+	imports["encoding/json"] = true
+	imports["fmt"] = true
+	names := make([]string, len(e.Values))
+	for i, v := range e.Values {
+		names[i] = v.Name
+	}
+	fmt.Fprintf(w, `
+// IsValid returns true when the value is one of the permitted %[1]s values.
+func (v %[1]s) IsValid() bool {
+    switch v {
+    case %[2]s:
+        return true
+    }
+    return false
+}
+`, e.Name, strings.Join(names, ", "))
+
+	if e.Type == "" {
+		imports["bytes"] = true
+		fmt.Fprintf(w, `
+func (v %[1]s) MarshalJSON() ([]byte, error) {
+    if v == "" {
+        return []byte("null"), nil
+    }
+    return []byte(v), nil
+}
+
+func (v *%[1]s) UnmarshalJSON(b []byte) error {
+    buf := new(bytes.Buffer)
+    if err := json.Compact(buf, b); err != nil {
+        return err
+    }
+    value := %[1]s(buf.String())
+    if !value.IsValid() {
+        return %[2]s
+    }
+    *v = value
+    return nil
+}
+`, e.Name, schemaError(validate, imports, "", schemaPath+"/enum", "enum",
+			fmt.Sprintf(`fmt.Sprintf("%%s is not a permitted %s value", buf.String())`, e.Name)))
+		return
+	}
+
+	fmt.Fprintf(w, `
+func (v *%[1]s) UnmarshalJSON(b []byte) error {
+    if string(b) == "null" {
+        return nil
+    }
+    var value %[2]s
+    if err := json.Unmarshal(b, &value); err != nil {
+        return err
+    }
+    if !%[1]s(value).IsValid() {
+        return %[3]s
+    }
+    *v = %[1]s(value)
+    return nil
+}
+`, e.Name, e.Type, schemaError(validate, imports, "", schemaPath+"/enum", "enum",
+		fmt.Sprintf(`fmt.Sprintf("%%s is not a permitted %s value", string(b))`, e.Name)))
+}
+
+func emitTupleCode(w io.Writer, t Tuple, schemaPath string, validate bool, imports map[string]bool) {
+	imports["encoding/json"] = true
+	values := make([]string, len(t.Items))
+	for i, f := range t.Items {
+		values[i] = "strct." + f.Name
+	}
+	fmt.Fprintf(w, `
+func (strct *%s) MarshalJSON() ([]byte, error) {
+    items := []interface{}{%s}
+`, t.Name, strings.Join(values, ", "))
+	if t.RestType != "" {
+		fmt.Fprintf(w, `    for _, v := range strct.Rest {
+        items = append(items, v)
+    }
+`)
+	}
+	fmt.Fprintf(w, `    return json.Marshal(items)
+}
+`)
+
+	fmt.Fprintf(w, `
+func (strct *%s) UnmarshalJSON(b []byte) error {
+`, t.Name)
+	if validate {
+		imports[typesImport] = true
+		fmt.Fprintf(w, "    var errs types.ValidationErrors\n")
+	}
+	fmt.Fprintf(w, `    var items []json.RawMessage
+    if err := json.Unmarshal(b, &items); err != nil {
+        return err
+    }
+`)
+	for i, f := range t.Items {
+		if validate {
+			fmt.Fprintf(w, `    if len(items) > %d {
+        errs.Merge("/%d", types.Unmarshal(items[%d], &strct.%s))
+    }
+`, i, i, i, f.Name)
+		} else {
+			fmt.Fprintf(w, `    if len(items) > %d {
+        if err := json.Unmarshal(items[%d], &strct.%s); err != nil {
+            return err
+        }
+    }
+`, i, i, f.Name)
+		}
+	}
+	if t.RestType != "" {
+		fmt.Fprintf(w, "    for i := %d; i < len(items); i++ {\n        var v %s\n", len(t.Items), t.RestType)
+		if validate {
+			imports["strconv"] = true
+			fmt.Fprintf(w, "        errs.Merge(\"/\"+strconv.Itoa(i), types.Unmarshal(items[i], &v))\n")
+		} else {
+			fmt.Fprintf(w, "        if err := json.Unmarshal(items[i], &v); err != nil {\n            return err\n        }\n")
+		}
+		fmt.Fprintf(w, "        strct.Rest = append(strct.Rest, v)\n    }\n")
+	} else {
+		keyword := "items"
+		if t.schema != nil && t.schema.itemsArray {
+			keyword = "additionalItems"
+		}
+		message := fmt.Sprintf("must have at most %d items", len(t.Items))
+		if validate {
+			fmt.Fprintf(w, "    if len(items) > %d {\n        errs.Add(\"\", %q, %q, %q)\n    }\n",
+				len(t.Items), schemaPath+"/"+keyword, keyword, message)
+		} else {
+			imports["errors"] = true
+			fmt.Fprintf(w, "    if len(items) > %d {\n        return errors.New(%q)\n    }\n", len(t.Items), message)
+		}
+	}
+	if validate {
+		fmt.Fprintf(w, "    return errs.Err()\n")
+	} else {
+		fmt.Fprintf(w, "    return nil\n")
+	}
+	fmt.Fprintf(w, "}\n")
+}
+
+// schemaError returns the expression of the error returned by an UnmarshalJSON method for a value which doesn't match
+// the schema, where message is an expression of type string. Without validation, the error is a plain error, so
+// that the generated code doesn't depend on the types package.
+func schemaError(validate bool, imports map[string]bool, instancePath, schemaPath, keyword, message string) string {
+	if validate {
+		return validationError(imports, instancePath, schemaPath, keyword, message)
+	}
+	if strings.HasPrefix(message, "fmt.Sprintf(") {
+		return "fmt.Errorf(" + strings.TrimPrefix(message, "fmt.Sprintf(")
+	}
+	imports["errors"] = true
+	return "errors.New(" + message + ")"
+}
+
+// validationError returns the expression of a types.ValidationError, where message is an expression of type string.
+func validationError(imports map[string]bool, instancePath, schemaPath, keyword, message string) string {
+	imports[typesImport] = true
+	if instancePath == "" {
+		return fmt.Sprintf("types.ValidationError{SchemaPath: %q, Keyword: %q, Message: %s}", schemaPath, keyword, message)
+	}
+	return fmt.Sprintf("types.ValidationError{InstancePath: %q, SchemaPath: %q, Keyword: %q, Message: %s}",
+		instancePath, schemaPath, keyword, message)
+}
+
+// variantValue returns the expression which stores the unmarshalled variable v in a union's Value field.
+func variantValue(v Variant) string {
+	if strings.HasPrefix(v.Type, "*") {
+		return "&v"
+	}
+	return "v"
+}
+
 func outputNameAndDescriptionComment(name, description string, w io.Writer) {
 	if strings.Index(description, "\n") == -1 {
 		fmt.Fprintf(w, "// %s %s\n", name, description)
@@ -289,5 +809,9 @@ func outputFieldDescriptionComment(description string, w io.Writer) {
 func cleanPackageName(pkg string) string {
 	pkg = strings.Replace(pkg, ".", "", -1)
 	pkg = strings.Replace(pkg, "-", "", -1)
+	// keywords can't be used as package names, e.g. "package type"
+	if token.Lookup(pkg).IsKeyword() {
+		pkg += "pkg"
+	}
 	return pkg
 }
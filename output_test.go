@@ -1,6 +1,10 @@
 package generate
 
 import (
+	"bytes"
+	"errors"
+	"go/format"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
@@ -89,3 +93,113 @@ func TestLineAndCharacterFromOffset(t *testing.T) {
 		}
 	}
 }
+
+func TestThatOutputIsFormattedWithSortedImports(t *testing.T) {
+	g := &Generator{
+		Structs: map[string]Struct{
+			"Example": {
+				Name: "Example",
+				Fields: map[string]Field{
+					"Identifier": {Name: "Identifier", JSONName: "id", Type: "types.UUID"},
+					"Created":    {Name: "Created", JSONName: "created", Type: "time.Time"},
+				},
+			},
+		},
+		imports: map[string]bool{
+			typesImport: true,
+			"time":      true,
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Output(buf, g, "test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual := buf.String()
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("the output could not be parsed: %v", err)
+	}
+	if actual != string(formatted) {
+		t.Errorf("expected the output to be formatted, but got:\n%s", actual)
+	}
+	timeIndex := strings.Index(actual, `"time"`)
+	typesIndex := strings.Index(actual, `"`+typesImport+`"`)
+	if timeIndex < 0 || typesIndex < 0 || timeIndex > typesIndex {
+		t.Errorf("expected the standard library imports to come first, but got:\n%s", actual)
+	}
+}
+
+// failingWriter returns an error for every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestThatWriteErrorsAreReturned(t *testing.T) {
+	g := &Generator{
+		Structs: map[string]Struct{
+			"Example": {Name: "Example", Fields: map[string]Field{"Name": {Name: "Name", JSONName: "name", Type: "string"}}},
+		},
+	}
+	if err := Output(failingWriter{}, g, "test"); err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the error from the writer, got %v", err)
+	}
+}
+
+func TestThatInvalidCodeIsReportedWithTheOffendingSnippet(t *testing.T) {
+	src := []byte("package test\n\ntype A struct {\n\tB string\n\tC ??\n}\n")
+
+	_, err := formatSource(src)
+	if err == nil {
+		t.Fatal("expected an error for invalid code")
+	}
+	if !strings.Contains(err.Error(), ">    5 | \tC ??") {
+		t.Errorf("expected the error to mark the offending line, but got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "     3 | type A struct {") {
+		t.Errorf("expected the error to include the surrounding lines, but got: %v", err)
+	}
+}
+
+func TestThatKeywordsAreNotUsedAsPackageNames(t *testing.T) {
+	tests := map[string]string{
+		"type":        "typepkg",
+		"example.com": "examplecom",
+		"my-types":    "mytypes",
+	}
+	for input, expected := range tests {
+		if actual := cleanPackageName(input); actual != expected {
+			t.Errorf("for %q expected %q, got %q", input, expected, actual)
+		}
+	}
+}
+
+func TestThatTheTypesPackageIsOnlyImportedForValidation(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root", "type": "object",
+		"properties": {
+			"colour": { "enum": [ "red", "green" ] },
+			"pet": { "oneOf": [ { "type": "string" }, { "type": "integer" } ] },
+			"point": { "type": "array", "prefixItems": [ { "type": "number" }, { "type": "number" } ], "items": false } },
+		"required": [ "colour" ],
+		"additionalProperties": false }`, &url.URL{Scheme: "file", Path: "output_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, validate := range []bool{false, true} {
+		g := New(root)
+		g.GenerateValidation = validate
+		if err := g.CreateTypes(); err != nil {
+			t.Fatal(err)
+		}
+		buf := new(bytes.Buffer)
+		if err := Output(buf, g, "test"); err != nil {
+			t.Fatal(err)
+		}
+		if imported := strings.Contains(buf.String(), `"`+typesImport+`"`); imported != validate {
+			t.Errorf("validate %v: expected the types package to be imported only for validation, got:\n%s", validate, buf.String())
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pointerError is returned when a JSON Pointer doesn't identify a schema within a document.
+type pointerError struct {
+	pointer string
+	// the part of the pointer which was evaluated before the error
+	at     string
+	reason string
+}
+
+func (e *pointerError) Error() string {
+	return fmt.Sprintf("the JSON Pointer %q can't be evaluated at %q: %s", e.pointer, e.at, e.reason)
+}
+
+// parsePointer splits a JSON Pointer, e.g. "/definitions/a~1b", into its reference tokens, e.g. "definitions" and
+// "a/b", as described by RFC 6901.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, &pointerError{pointer: pointer, reason: "a JSON Pointer must start with \"/\""}
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || token[j+1] != '0' && token[j+1] != '1') {
+				return nil, &pointerError{pointer: pointer, at: formatPointer(tokens[:i]),
+					reason: fmt.Sprintf("%q contains \"~\" which isn't followed by \"0\" or \"1\"", token)}
+			}
+		}
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// formatPointer joins reference tokens into a JSON Pointer, escaping them.
+func formatPointer(tokens []string) string {
+	var buf bytes.Buffer
+	for _, token := range tokens {
+		buf.WriteString("/")
+		buf.WriteString(escapePointerToken(token))
+	}
+	return buf.String()
+}
+
+// escapePointerToken escapes "~" as "~0" and "/" as "~1", so that the token can be part of a JSON Pointer.
+func escapePointerToken(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// evaluatePointer returns the JSON value at the reference tokens within the document.
+func evaluatePointer(document json.RawMessage, tokens []string, pointer string) (json.RawMessage, error) {
+	value := document
+	for i, token := range tokens {
+		fail := func(reason string) error {
+			return &pointerError{pointer: pointer, at: formatPointer(tokens[:i]), reason: reason}
+		}
+		switch firstByte(value) {
+		case '{':
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(value, &object); err != nil {
+				return nil, fail(err.Error())
+			}
+			member, ok := object[token]
+			if !ok {
+				return nil, fail(fmt.Sprintf("the object has no %q member", token))
+			}
+			value = member
+		case '[':
+			var array []json.RawMessage
+			if err := json.Unmarshal(value, &array); err != nil {
+				return nil, fail(err.Error())
+			}
+			// array indexes don't have leading zeros, and "-" refers past the end of the array
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || (token[0] == '0' && len(token) > 1) || token[0] == '+' {
+				return nil, fail(fmt.Sprintf("%q is not an array index", token))
+			}
+			if index >= len(array) {
+				return nil, fail(fmt.Sprintf("the index %d is past the end of the array of %d items", index, len(array)))
+			}
+			value = array[index]
+		default:
+			return nil, fail(fmt.Sprintf("%s is not an object or an array", value))
+		}
+	}
+	return value, nil
+}
+
+func firstByte(value json.RawMessage) byte {
+	value = bytes.TrimSpace(value)
+	if len(value) == 0 {
+		return 0
+	}
+	return value[0]
+}
+
+// rawDocument returns the JSON that the schema was parsed from, or nil if it wasn't parsed, e.g. because it was
+// constructed in code.
+func (schema *Schema) rawDocument() json.RawMessage {
+	if schema.IsRoot() {
+		return schema.raw
+	}
+	parent := schema.Parent.rawDocument()
+	if parent == nil {
+		return nil
+	}
+	// the path element is a keyword, optionally followed by a key or index, e.g. "properties/a~1b" for the key "a/b"
+	tokens, err := parsePointer("/" + schema.PathElement)
+	if err != nil {
+		return nil
+	}
+	value, err := evaluatePointer(parent, tokens, "")
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// resolvePointer returns the schema at the JSON Pointer within the document. The pointer is evaluated over the raw
+// document, as described by RFC 6901, so that any location which holds a schema can be referenced, and not only
+// those added by updateURIs. It returns nil when the document wasn't parsed from JSON.
+func (r *RefResolver) resolvePointer(document *Schema, pointer string) (*Schema, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	raw := document.rawDocument()
+	if raw == nil {
+		return nil, nil
+	}
+	value, err := evaluatePointer(raw, tokens, pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	// use the schema which was already parsed, where the pointer leads to one
+	parent, i := document, 0
+	for i < len(tokens) {
+		child, n := parent.findSubSchema(tokens[i:])
+		if child == nil {
+			break
+		}
+		parent, i = child, i+n
+	}
+	if i == len(tokens) {
+		return parent, nil
+	}
+
+	if firstByte(value) != '{' {
+		return nil, &pointerError{pointer: pointer, at: pointer, reason: fmt.Sprintf("%s is not a schema", value)}
+	}
+	schema := &Schema{}
+	if err := json.Unmarshal(value, schema); err != nil {
+		return nil, &pointerError{pointer: pointer, at: pointer, reason: "the schema can't be parsed: " + err.Error()}
+	}
+	schema.Parent = parent
+	schema.JSONKey = tokens[len(tokens)-1]
+	schema.PathElement = strings.TrimPrefix(formatPointer(tokens[i:]), "/")
+	schema.updateParentLinks()
+	schema.updatePathElements()
+	return schema, nil
+}
+
+// findSubSchema returns the schema nested directly within the schema at the start of the reference tokens, and the
+// number of tokens that lead to it, e.g. 2 for "properties" and "name".
+func (schema *Schema) findSubSchema(tokens []string) (*Schema, int) {
+	for _, s := range schema.SubSchemas() {
+		if s.Keyword != tokens[0] {
+			continue
+		}
+		if s.Key == "" {
+			return s.Schema, 1
+		}
+		if len(tokens) > 1 && s.Key == tokens[1] {
+			return s.Schema, 2
+		}
+	}
+	return nil, 0
+}
@@ -0,0 +1,117 @@
+package generate
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestThatJSONPointersAreParsed(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		expected []string
+		err      string
+	}{
+		{pointer: "", expected: nil},
+		{pointer: "/", expected: []string{""}},
+		{pointer: "/definitions/a~1b", expected: []string{"definitions", "a/b"}},
+		{pointer: "/definitions/a~01", expected: []string{"definitions", "a~1"}},
+		{pointer: "/definitions/~2", err: `the JSON Pointer "/definitions/~2" can't be evaluated at "/definitions": "~2" contains "~" which isn't followed by "0" or "1"`},
+		{pointer: "definitions", err: `the JSON Pointer "definitions" can't be evaluated at "": a JSON Pointer must start with "/"`},
+	}
+	for _, test := range tests {
+		tokens, err := parsePointer(test.pointer)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: expected error %q, got %v", test.pointer, test.err, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(tokens, test.expected) {
+			t.Errorf("%s: expected %q, got %q, %v", test.pointer, test.expected, tokens, err)
+		}
+		if actual := formatPointer(tokens); actual != test.pointer && test.pointer != "/definitions/a~01" {
+			t.Errorf("%s: expected the tokens to be formatted as the pointer, got %s", test.pointer, actual)
+		}
+	}
+}
+
+func TestThatJSONPointersAreEvaluated(t *testing.T) {
+	document := json.RawMessage(`{ "allOf": [ { "type": "string" } ], "": { " ": 1 }, "title": "a" }`)
+	tests := []struct {
+		pointer  string
+		expected string
+		err      string
+	}{
+		{pointer: "/allOf/0/type", expected: `"string"`},
+		{pointer: "// ", expected: `1`},
+		{pointer: "/allOf/1", err: `the JSON Pointer "/allOf/1" can't be evaluated at "/allOf": the index 1 is past the end of the array of 1 items`},
+		{pointer: "/allOf/01", err: `the JSON Pointer "/allOf/01" can't be evaluated at "/allOf": "01" is not an array index`},
+		{pointer: "/allOf/-", err: `the JSON Pointer "/allOf/-" can't be evaluated at "/allOf": "-" is not an array index`},
+		{pointer: "/anyOf/0", err: `the JSON Pointer "/anyOf/0" can't be evaluated at "": the object has no "anyOf" member`},
+		{pointer: "/title/0", err: `the JSON Pointer "/title/0" can't be evaluated at "/title": "a" is not an object or an array`},
+	}
+	for _, test := range tests {
+		tokens, err := parsePointer(test.pointer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := evaluatePointer(document, tokens, test.pointer)
+		if test.err != "" {
+			if err == nil || err.Error() != test.err {
+				t.Errorf("%s: expected error %q, got %v", test.pointer, test.err, err)
+			}
+			continue
+		}
+		if err != nil || string(value) != test.expected {
+			t.Errorf("%s: expected %s, got %s, %v", test.pointer, test.expected, value, err)
+		}
+	}
+}
+
+func TestThatUnresolvablePointersAreReported(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root",
+		"properties": { "a": { "$ref": "#/allOf/1/properties/x" } }, "allOf": [ { "type": "object" } ] }`,
+		&url.URL{Scheme: "file", Path: "/root.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = New(root).CreateTypes()
+	expected := `processReference: reference "#/allOf/1/properties/x" at "#/properties/a" can't be resolved: ` +
+		`the JSON Pointer "/allOf/1/properties/x" can't be evaluated at "/allOf": the index 1 is past the end of the array of 1 items`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestThatSchemaPathsAreJSONPointers(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "title": "Root",
+		"properties": { "a/b": { "$ref": "#/definitions/c~1d" }, "e~f": { "type": "integer", "maximum": 9223372036854775807 } },
+		"definitions": { "c/d": { "title": "CD", "type": "object" } } }`,
+		&url.URL{Scheme: "file", Path: "/root.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New(root)
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := NewRefResolver(nil)
+	for schema, expected := range map[*Schema]string{
+		root.Properties["a/b"]:  "#/properties/a~1b",
+		root.Properties["e~f"]:  "#/properties/e~0f",
+		root.Definitions["c/d"]: "#/definitions/c~1d",
+	} {
+		if actual := resolver.GetPath(schema); actual != expected {
+			t.Errorf("expected the path %s, got %s", expected, actual)
+		}
+		if schema.rawDocument() == nil {
+			t.Errorf("expected the JSON of %s to be found", expected)
+		}
+	}
+	if typ := g.Structs["Root"].Fields["AB"].Type; typ != "*CD" {
+		t.Errorf("expected the reference to be resolved to *CD, got %s", typ)
+	}
+}
@@ -12,12 +12,20 @@ type RefResolver struct {
 	schemas []*Schema
 	//           k=uri     v=Schema
 	pathToSchema map[string]*Schema
+
+	// Loaders read the documents which are referenced, but weren't passed to the resolver, keyed by URI scheme.
+	// Files are read from disk by default.
+	Loaders map[string]Loader
+
+	// Catalog maps the URIs of referenced documents to local files, which are read in preference to the Loaders.
+	Catalog Catalog
 }
 
 // NewRefResolver creates a reference resolver.
 func NewRefResolver(schemas []*Schema) *RefResolver {
 	return &RefResolver{
 		schemas: schemas,
+		Loaders: map[string]Loader{"file": FileLoader{}},
 	}
 }
 
@@ -51,7 +59,7 @@ func (r *RefResolver) GetPath(schema *Schema) string {
 
 // GetSchemaByReference returns the schema.
 func (r *RefResolver) GetSchemaByReference(schema *Schema) (*Schema, error) {
-	u, err := url.Parse(schema.GetRoot().ID())
+	u, err := schema.baseURI()
 	if err != nil {
 		return nil, err
 	}
@@ -61,12 +69,104 @@ func (r *RefResolver) GetSchemaByReference(schema *Schema) (*Schema, error) {
 	}
 	resolvedPath := u.ResolveReference(ref)
 	path, ok := r.pathToSchema[resolvedPath.String()]
+	loader, canLoad := r.Loaders[resolvedPath.Scheme]
+	if _, inCatalog := r.Catalog.Path(resolvedPath); inCatalog {
+		loader, canLoad = r.Catalog, true
+	}
+	if !ok && canLoad {
+		// the reference is to a document that wasn't passed in, so load it relative to the referencing document
+		if err := r.load(*resolvedPath, loader, schema.GetRoot().schemaKeyRequired); err != nil {
+			return nil, err
+		}
+		path, ok = r.pathToSchema[resolvedPath.String()]
+	}
+	if !ok && strings.HasPrefix(resolvedPath.Fragment, "/") {
+		// fall back to evaluating the pointer, which can refer to schemas that updateURIs doesn't add
+		documentURI := *resolvedPath
+		documentURI.Fragment = ""
+		if document, found := r.pathToSchema[documentURI.String()]; found {
+			if path, err = r.resolvePointer(document, resolvedPath.Fragment); err != nil {
+				return nil, err
+			}
+			if ok = path != nil; ok {
+				r.pathToSchema[resolvedPath.String()] = path
+			}
+		}
+	}
 	if !ok {
 		return nil, errors.New("refresolver.GetSchemaByReference: reference not found: " + schema.Reference)
 	}
 	return path, nil
 }
 
+// baseURI returns the URI that references within the schema are resolved against, which is set by the $id of the
+// closest schema that has one.
+func (schema *Schema) baseURI() (*url.URL, error) {
+	if schema.IsRoot() {
+		return url.Parse(schema.ID())
+	}
+	base, err := schema.Parent.baseURI()
+	if err != nil || schema.ID() == "" {
+		return base, err
+	}
+	id, err := url.Parse(schema.ID())
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(id), nil
+}
+
+// loadError is returned when a document referenced by a schema can't be loaded.
+type loadError struct {
+	uri string
+	err error
+}
+
+func (e *loadError) Error() string {
+	return fmt.Sprintf("failed to load %s: %v", e.uri, e.err)
+}
+
+// load reads the document at the URI, and adds the paths of its schemas, so that references to it can be resolved.
+// When the document has an $id of its own, its paths are also added relative to the URI it was loaded from.
+func (r *RefResolver) load(uri url.URL, loader Loader, schemaKeyRequired bool) error {
+	uri.Fragment = ""
+	if _, loaded := r.pathToSchema[uri.String()]; loaded {
+		return nil
+	}
+	b, err := loader.Load(&uri)
+	if err != nil {
+		return &loadError{uri: uri.String(), err: err}
+	}
+	schema, err := parseInputFile(b, uri.String(), &uri, schemaKeyRequired)
+	if err != nil {
+		return &loadError{uri: uri.String(), err: err}
+	}
+	id, err := url.Parse(schema.ID())
+	if err != nil {
+		return &loadError{uri: uri.String(), err: err}
+	}
+	id.Fragment = ""
+	if existing, ok := r.pathToSchema[id.String()]; ok {
+		// the document was passed in, but is referenced by its file name rather than its $id
+		schema = existing
+	} else {
+		r.schemas = append(r.schemas, schema)
+		if err := r.mapPaths(schema); err != nil {
+			return &loadError{uri: uri.String(), err: err}
+		}
+	}
+	if _, ok := r.pathToSchema[uri.String()]; ok {
+		return nil
+	}
+	if err := r.InsertURI(uri.String(), schema); err != nil {
+		return err
+	}
+	if err := r.InsertURI(uri.String()+"#", schema); err != nil {
+		return err
+	}
+	return r.updateURIs(schema, uri)
+}
+
 func (r *RefResolver) mapPaths(schema *Schema) error {
 	rootURI := &url.URL{}
 	id := schema.ID()
@@ -90,68 +190,70 @@ func (r *RefResolver) mapPaths(schema *Schema) error {
 			return err
 		}
 	}
-	r.updateURIs(schema, *rootURI, false, false)
-	return nil
+	return r.updateURIs(schema, *rootURI)
+}
+
+// updateURIs adds the URIs of the sub-schemas of the schema, relative to its base URI.
+func (r *RefResolver) updateURIs(schema *Schema, baseURI url.URL) error {
+	var err error
+	Walk(uriMapper{r: r, baseURI: baseURI, err: &err}, schema)
+	return err
+}
+
+// uriMapper adds the URIs of the schemas it visits to the resolver, as JSON Pointers relative to the base URI of
+// their parent, and relative to the base URI they set with their own $id.
+type uriMapper struct {
+	r       *RefResolver
+	baseURI url.URL
+	// true when the base URI has changed since the schema containing the fragment, so that the fragments of the
+	// current base URI don't identify its sub-schemas
+	ignoreFragments bool
+	err             *error
 }
 
-// create a map of base URIs
-func (r *RefResolver) updateURIs(schema *Schema, baseURI url.URL, checkCurrentID bool, ignoreFragments bool) error {
-	// already done for root, and if schema sets a new base URI
-	if checkCurrentID {
-		id := schema.ID()
-		if id != "" {
+func (v uriMapper) Visit(s SubSchema) Visitor {
+	if *v.err != nil {
+		return nil
+	}
+	schema, baseURI, ignoreFragments := s.Schema, v.baseURI, v.ignoreFragments
+	// the schema that the walk starts from has already been added
+	if s.Keyword != "" {
+		baseURI.Fragment += "/" + s.PathElement()
+		if *v.err = v.r.InsertURI(baseURI.String(), schema); *v.err != nil {
+			return nil
+		}
+		// when we're coming from part of the tree where the baseURI has changed, the $id has already been added
+		// relative to the new base, and can't be resolved against the current one
+		if id := schema.ID(); id != "" && !ignoreFragments {
 			newBase, err := url.Parse(id)
 			if err != nil {
-				return err
+				*v.err = err
+				return nil
 			}
-			// if it's a JSON fragment and we're coming from part of the tree where the baseURI has changed, we need to
-			// ignore the fragment, since it won't be resolvable under the current baseURI.
-			if !(strings.HasPrefix(id, "#") && ignoreFragments) {
-				// map all the subschema under the new base
-				resolved := baseURI.ResolveReference(newBase)
-				if err := r.InsertURI(resolved.String(), schema); err != nil {
-					return err
-				}
-				if resolved.Fragment == "" {
-					if err := r.InsertURI(resolved.String()+"#", schema); err != nil {
-						return err
-					}
-				}
-				if err := r.updateURIs(schema, *resolved, false, false); err != nil {
-					return err
+			// map all the subschema under the new base
+			resolved := baseURI.ResolveReference(newBase)
+			if *v.err = v.r.InsertURI(resolved.String(), schema); *v.err != nil {
+				return nil
+			}
+			if resolved.Fragment == "" {
+				if *v.err = v.r.InsertURI(resolved.String()+"#", schema); *v.err != nil {
+					return nil
 				}
-				// and continue to map all subschema under the old base (except for fragments)
-				ignoreFragments = true
 			}
+			Walk(uriMapper{r: v.r, baseURI: *resolved, err: v.err}, schema)
+			// and continue to map all subschema under the old base (except for fragments)
+			ignoreFragments = true
 		}
 	}
-	for k, subSchema := range schema.Definitions {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/definitions/" + k
-		if err := r.InsertURI(newBaseURI.String(), subSchema); err != nil {
-			return err
+	// a plain-name fragment, e.g. "#address", identifies the schema within the current base URI
+	if schema.Anchor != "" && !ignoreFragments {
+		anchorURI := baseURI
+		anchorURI.Fragment = schema.Anchor
+		if *v.err = v.r.InsertURI(anchorURI.String(), schema); *v.err != nil {
+			return nil
 		}
-		r.updateURIs(subSchema, newBaseURI, true, ignoreFragments)
 	}
-	for k, subSchema := range schema.Properties {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/properties/" + k
-		if err := r.InsertURI(newBaseURI.String(), subSchema); err != nil {
-			return err
-		}
-		r.updateURIs(subSchema, newBaseURI, true, ignoreFragments)
-	}
-	if schema.AdditionalProperties != nil {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/additionalProperties"
-		r.updateURIs((*Schema)(schema.AdditionalProperties), newBaseURI, true, ignoreFragments)
-	}
-	if schema.Items != nil {
-		newBaseURI := baseURI
-		newBaseURI.Fragment += "/items"
-		r.updateURIs(schema.Items, newBaseURI, true, ignoreFragments)
-	}
-	return nil
+	return uriMapper{r: v.r, baseURI: baseURI, ignoreFragments: ignoreFragments, err: v.err}
 }
 
 // InsertURI to the references.
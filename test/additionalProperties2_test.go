@@ -35,7 +35,7 @@ func TestMarshalUnmarshal(t *testing.T) {
 					PoBox: &additionalProperties2.PoBox{
 						Suburb: "Smallville",
 					},
-					AdditionalProperties: map[string]map[string]*additionalProperties2.Anonymous1{
+					AdditionalProperties: map[string]map[string]*additionalProperties2.HairyAdditionalProperties{
 						"red": {
 							"blue": {
 								Color: "green",
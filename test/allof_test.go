@@ -0,0 +1,31 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/allof_gen"
+)
+
+func TestAllOf(t *testing.T) {
+	d := &allof.Dog{}
+	if err := json.Unmarshal([]byte(`{"name":"Rex","legs":4,"breed":"collie","owner":{"name":"Tim"}}`), d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Name != "Rex" || d.Legs != 4 || d.Breed != "collie" || d.Owner.Name != "Tim" {
+		t.Errorf("unexpected result: %+v", d)
+	}
+	if err := json.Unmarshal([]byte(`{"name":"Rex"}`), &allof.Dog{}); err == nil {
+		t.Error("expected an error when the required breed field from the second sub-schema is missing")
+	}
+	if err := json.Unmarshal([]byte(`{"breed":"collie"}`), &allof.Dog{}); err == nil {
+		t.Error("expected an error when the required name field from the referenced sub-schema is missing")
+	}
+}
+
+func TestAllOfWithASingleReferenceReusesTheReferencedType(t *testing.T) {
+	d := allof.Dog{Owner: &allof.Person{Name: "Tim"}}
+	if d.Owner.Name != "Tim" {
+		t.Error("thats the test")
+	}
+}
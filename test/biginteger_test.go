@@ -0,0 +1,31 @@
+package test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/a-h/generate/test/biginteger_gen"
+)
+
+func TestThatLargeIntegersCanBeBigInts(t *testing.T) {
+	var a biginteger.Account
+	if err := json.Unmarshal([]byte(`{"balance":-123456789012345678901234567890,"level":3}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	if a.Balance == nil || a.Balance.Cmp(expected) != 0 || a.Id != nil || a.Level != 3 {
+		t.Errorf("unexpected values: %+v", a)
+	}
+	if err := a.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	a.Id, _ = new(big.Int).SetString("100000000000000000001", 10)
+	a.Balance = nil
+	err := a.Validate()
+	expectedErr := "\"balance\" is required but was not present; /id: must be less than or equal to 100000000000000000000"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected %q, got %v", expectedErr, err)
+	}
+}
@@ -0,0 +1,34 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/a-h/generate/test/collisions_gen"
+)
+
+func TestThatClashingTypeNamesArePrefixedWithTheirParent(t *testing.T) {
+	o := collisions.Order{
+		Customer: &collisions.Customer{
+			Address: &collisions.Address{Street: "1 High Street"},
+		},
+		Supplier: &collisions.Supplier{
+			Address: &collisions.SupplierAddress{Lines: []string{"Unit 2", "Industrial Estate"}},
+		},
+	}
+	if o.Customer.Address.Street != "1 High Street" || len(o.Supplier.Address.Lines) != 2 {
+		t.Errorf("unexpected values: %+v", o)
+	}
+}
+
+func TestThatUnionInterfacesAndEnumConstantsArePrefixedWhenTheyClash(t *testing.T) {
+	var value collisions.OrderPetValue = collisions.PetString("Rex")
+	o := collisions.Order{
+		Pet:  &collisions.Pet{Value: value},
+		Size: collisions.OrderSizeLarge,
+	}
+	_ = collisions.PetValue{Name: "Rex"}
+	_ = collisions.SizeLarge{Width: 2}
+	if o.Size != "large" {
+		t.Errorf("unexpected size: %v", o.Size)
+	}
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/generate/test/defaults_gen"
+)
+
+func TestThatConstructorsSetDefaultValues(t *testing.T) {
+	c := defaults.NewConfig()
+	if c.Name == nil || *c.Name != "anonymous" || c.Port == nil || *c.Port != 8080 || c.Ratio == nil || *c.Ratio != 0.5 {
+		t.Errorf("unexpected values: %+v", c)
+	}
+	if c.Debug == nil || !*c.Debug || c.Level == nil || *c.Level != defaults.LevelInfo || c.Timeout == nil || *c.Timeout != 30 {
+		t.Errorf("unexpected values: %+v", c)
+	}
+	if !reflect.DeepEqual(c.Tags, []string{"a", "b"}) {
+		t.Errorf("unexpected tags: %v", c.Tags)
+	}
+	// the default object is unmarshalled, which sets its own defaults
+	if c.Server == nil || c.Server.Host == nil || *c.Server.Host != "localhost" || c.Server.Tls == nil || !*c.Server.Tls {
+		t.Errorf("unexpected server: %+v", c.Server)
+	}
+
+	s := defaults.NewServer()
+	if s.Host == nil || *s.Host != "localhost" || s.Tls != nil {
+		t.Errorf("unexpected server: %+v", s)
+	}
+}
+
+func TestThatMissingPropertiesAreSetToTheirDefaultsWhenUnmarshalling(t *testing.T) {
+	var c defaults.Config
+	if err := json.Unmarshal([]byte(`{"name":"test","port":0,"limits":[{},{"max":5}]}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if *c.Name != "test" || *c.Port != 0 || *c.Ratio != 0.5 || *c.Timeout != 30 {
+		t.Errorf("unexpected values: %+v", c)
+	}
+	if len(c.Limits) != 2 || *c.Limits[0].Max != 10 || *c.Limits[1].Max != 5 {
+		t.Errorf("unexpected limits: %+v", c.Limits)
+	}
+	if c.Server == nil || !*c.Server.Tls || *c.Server.Host != "localhost" {
+		t.Errorf("unexpected server: %+v", c.Server)
+	}
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/generate/test/draft2020_gen"
+	"github.com/a-h/generate/types"
+)
+
+func TestThatDefsAndAnchorsAreResolved(t *testing.T) {
+	s := draft2020.Shipment{}
+	data := `{"id":"1","origin":{"code":"LHR","name":"Heathrow"},"destination":{"code":"JFK"}}`
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatal(err)
+	}
+	// the $ref siblings are merged with the referenced schema
+	if s.Origin == nil || s.Origin.Code != "LHR" || s.Origin.Name != "Heathrow" {
+		t.Errorf("unexpected origin: %+v", s.Origin)
+	}
+	if s.Destination == nil || s.Destination.Code != "JFK" {
+		t.Errorf("unexpected destination: %+v", s.Destination)
+	}
+}
+
+func TestThatTuplesAreMarshalledAsArrays(t *testing.T) {
+	s := draft2020.Shipment{}
+	if err := json.Unmarshal([]byte(`{"id":"1","route":["north",2,"a","b"],"position":[51.5,-0.1]}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	expected := &draft2020.Route{Item1: "north", Item2: 2, Rest: []string{"a", "b"}}
+	if !reflect.DeepEqual(s.Route, expected) {
+		t.Errorf("expected %+v, got %+v", expected, s.Route)
+	}
+	if s.Position == nil || s.Position.Item1 != 51.5 || s.Position.Item2 != -0.1 {
+		t.Errorf("unexpected position: %+v", s.Position)
+	}
+
+	b, err := json.Marshal(s.Route)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `["north",2,"a","b"]` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
+
+func TestThatClosedTuplesRejectExtraItems(t *testing.T) {
+	err := json.Unmarshal([]byte(`{"id":"1","position":[1,2,3]}`), &draft2020.Shipment{})
+	errs, ok := err.(types.ValidationErrors)
+	if !ok || len(errs) != 1 || errs[0].InstancePath != "/position" || errs[0].Keyword != "items" {
+		t.Errorf("expected an items error for /position, got %v", err)
+	}
+}
+
+func TestThatUnevaluatedPropertiesAreRejected(t *testing.T) {
+	err := json.Unmarshal([]byte(`{"id":"1","other":true}`), &draft2020.Shipment{})
+	if err == nil || !strings.Contains(err.Error(), "/other: additional property not allowed") {
+		t.Errorf("expected an error for the unevaluated property, got %v", err)
+	}
+	// properties from dependentSchemas are evaluated
+	if err := json.Unmarshal([]byte(`{"id":"1","creditCard":"1234","billingAddress":"x","cardholder":"Tim"}`), &draft2020.Shipment{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestThatDependentRequiredIsValidated(t *testing.T) {
+	s := draft2020.Shipment{Id: "1", CreditCard: "1234"}
+	err := s.Validate()
+	if err == nil || err.Error() != `"billingAddress" is required when "creditCard" is present` {
+		t.Errorf("expected a dependentRequired error, got %v", err)
+	}
+	s.BillingAddress = "1 High Street"
+	if err := s.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestThatTupleItemsAreValidated(t *testing.T) {
+	s := draft2020.Shipment{Id: "1", Route: &draft2020.Route{Item1: "a", Item2: -1, Rest: []string{"abcd"}}}
+	err := s.Validate()
+	errs, ok := err.(types.ValidationErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if errs[0].InstancePath != "/route/1" || errs[0].SchemaPath != "#/properties/route/prefixItems/1/minimum" {
+		t.Errorf("unexpected error: %+v", errs[0])
+	}
+	if errs[1].InstancePath != "/route/2" || errs[1].SchemaPath != "#/properties/route/items/maxLength" {
+		t.Errorf("unexpected error: %+v", errs[1])
+	}
+}
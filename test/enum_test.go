@@ -0,0 +1,52 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/enum_gen"
+)
+
+func TestEnum(t *testing.T) {
+	s := &enum.Status{}
+	if err := json.Unmarshal([]byte(`{"favouritecat":"B","rating":-1,"weight":0.5,"anything":{"b":2}}`), s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Favouritecat != enum.FavouritecatB {
+		t.Errorf("expected B, got %v", s.Favouritecat)
+	}
+	if s.Rating != enum.RatingMinus1 {
+		t.Errorf("expected -1, got %v", s.Rating)
+	}
+	if s.Weight != enum.Weight0Point5 {
+		t.Errorf("expected 0.5, got %v", s.Weight)
+	}
+	if s.Anything != enum.AnythingValue5 {
+		t.Errorf("expected {\"b\":2}, got %v", s.Anything)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"anything":{"b":2},"favouritecat":"B","rating":-1,"weight":0.5}` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
+
+func TestEnumRejectsValuesOutsideTheSet(t *testing.T) {
+	tests := []string{
+		`{"favouritecat":"D"}`,
+		`{"favouritecat":"A","rating":2}`,
+		`{"favouritecat":"A","weight":2}`,
+		`{"favouritecat":"A","anything":"b"}`,
+	}
+	for _, test := range tests {
+		if err := json.Unmarshal([]byte(test), &enum.Status{}); err == nil {
+			t.Errorf("expected an error for %s", test)
+		}
+	}
+	if !enum.AnythingNull.IsValid() || enum.Favouritecat("D").IsValid() {
+		t.Error("unexpected IsValid result")
+	}
+}
@@ -0,0 +1,42 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/fieldnames_gen"
+)
+
+func TestThatClashingFieldNamesAreKept(t *testing.T) {
+	data := `{"foo-bar":"a","foo_bar":2,"fooBar":true,"$type":"record","1st":"c","other":"b"}`
+
+	r := fieldnames.Record{}
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.FooBar2 != "a" || r.FooBar3 != 2 || !r.FooBar || r.Kind != "record" || r.AdditionalProperties["other"] != "b" {
+		t.Errorf("unexpected values: %+v", r)
+	}
+
+	b, err := json.Marshal(&r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := map[string]interface{}{}
+	if err := json.Unmarshal(b, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{"foo-bar": "a", "foo_bar": 2.0, "fooBar": true, "$type": "record", "1st": "c", "other": "b"}
+	for k, v := range expected {
+		if roundTripped[k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, roundTripped[k])
+		}
+	}
+}
+
+func TestThatClashingRequiredFieldsAreChecked(t *testing.T) {
+	err := json.Unmarshal([]byte(`{"foo-bar":"a","1st":"c"}`), &fieldnames.Record{})
+	if err == nil || err.Error() != `"foo_bar" is required but was not present` {
+		t.Errorf("expected an error for the missing foo_bar, got %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/filerefs_gen"
+)
+
+func TestThatReferencedFilesAreLoaded(t *testing.T) {
+	var o filerefs.Order
+	if err := json.Unmarshal([]byte(`{"shipping":{"street":"1 High Street","country":"GB"},"total":{"amount":9.99,"currency":"GBP"}}`), &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.Shipping.Country != filerefs.CountryGB || o.Total.Currency != "GBP" {
+		t.Errorf("unexpected values: %+v %+v", o.Shipping, o.Total)
+	}
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/a-h/generate/test/formats_gen"
+	"github.com/a-h/generate/types"
+)
+
+func TestThatFormatsAreUnmarshalledIntoGolangTypes(t *testing.T) {
+	data := `{
+		"id": "123e4567-e89b-12d3-a456-426614174000",
+		"occurred": "2018-03-04T05:06:07Z",
+		"day": "2018-03-04",
+		"length": "P1DT2H",
+		"link": "https://example.com/events?id=1",
+		"source": "192.168.0.1",
+		"payload": "aGVsbG8=",
+		"email": "test@example.com"
+	}`
+	e := formats.Event{}
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Id.String() != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("unexpected id: %v", e.Id)
+	}
+	if !e.Occurred.Equal(time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Errorf("unexpected occurred: %v", e.Occurred)
+	}
+	if e.Day != (types.Date{Year: 2018, Month: time.March, Day: 4}) {
+		t.Errorf("unexpected day: %v", e.Day)
+	}
+	if e.Length != (types.Duration{Days: 1, Time: 2 * time.Hour}) {
+		t.Errorf("unexpected length: %v", e.Length)
+	}
+	if e.Link.Host != "example.com" || e.Link.Query().Get("id") != "1" {
+		t.Errorf("unexpected link: %v", e.Link)
+	}
+	if !e.Source.Equal(net.IPv4(192, 168, 0, 1)) {
+		t.Errorf("unexpected source: %v", e.Source)
+	}
+	if string(e.Payload) != "hello" {
+		t.Errorf("unexpected payload: %q", e.Payload)
+	}
+	// formats without a golang type are strings
+	if e.Email != "test@example.com" {
+		t.Errorf("unexpected email: %v", e.Email)
+	}
+
+	b, err := json.Marshal(&e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTrip formats.Event
+	if err := json.Unmarshal(b, &roundTrip); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", b, err)
+	}
+	if roundTrip.Length != e.Length || roundTrip.Day != e.Day || roundTrip.Link.String() != e.Link.String() {
+		t.Errorf("expected %+v, got %+v", e, roundTrip)
+	}
+}
+
+func TestThatInvalidFormatsAreRejected(t *testing.T) {
+	data := `{"id": "not-a-uuid", "occurred": "2018-03-04T05:06:07Z", "day": "yesterday"}`
+	err := json.Unmarshal([]byte(data), &formats.Event{})
+	errs, ok := err.(types.ValidationErrors)
+	if !ok || len(errs) != 2 || errs[0].InstancePath != "/day" || errs[1].InstancePath != "/id" {
+		t.Errorf("expected errors for /day and /id, got %v", err)
+	}
+}
+
+func TestThatDependenciesOfFormattedPropertiesAreValidated(t *testing.T) {
+	e := formats.Event{Ended: time.Date(2018, 3, 4, 6, 0, 0, 0, time.UTC)}
+	err := e.Validate()
+	if err == nil || err.Error() != `"length" is required when "ended" is present` {
+		t.Errorf("expected a dependencies error, got %v", err)
+	}
+	e.Length = types.Duration{Time: time.Hour}
+	if err := e.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/initialisms_gen"
+)
+
+func TestThatInitialismsAndReservedNamesAreHandled(t *testing.T) {
+	data := `{"id":"1","userId":"2","httpStatus":200,"homepage_url":"https://example.com","skuCode":"A1","validate":true,"1st":"first","problem":{"message":"m"}}`
+
+	a := initialisms.Account{}
+	if err := json.Unmarshal([]byte(data), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != "1" || a.UserID != "2" || a.HTTPStatus != 200 || a.HomepageURL != "https://example.com" || a.SKUCode != "A1" {
+		t.Errorf("unexpected values: %+v", a)
+	}
+	if !a.Validate2 || a.X_1st != "first" || a.Problem.Message != "m" {
+		t.Errorf("unexpected values: %+v", a)
+	}
+	if err := a.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	var problem initialisms.Error = *a.Problem
+	if problem.Message != "m" {
+		t.Errorf("unexpected problem: %+v", problem)
+	}
+}
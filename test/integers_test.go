@@ -0,0 +1,72 @@
+package test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/generate/test/integers_gen"
+)
+
+func TestThatIntegersUseTheNarrowestType(t *testing.T) {
+	var p integers.Packet
+	fields := map[string]interface{}{
+		"Version":  uint8(0),
+		"Offset":   int8(0),
+		"Port":     uint16(0),
+		"Delta":    int32(0),
+		"Length":   uint32(0),
+		"Sequence": int64(0),
+		"Size":     uint64(0),
+		"Position": int64(0),
+		"Count":    0,
+		"Checksum": json.Number(""),
+		"Samples":  []int16(nil),
+		"Flags":    []uint16(nil),
+	}
+	v := reflect.ValueOf(p)
+	for name, expected := range fields {
+		if actual := v.FieldByName(name).Type(); actual != reflect.TypeOf(expected) {
+			t.Errorf("expected %s to be %v, got %v", name, reflect.TypeOf(expected), actual)
+		}
+	}
+}
+
+func TestThatLargeIntegersAreKeptExactly(t *testing.T) {
+	var p integers.Packet
+	if err := json.Unmarshal([]byte(`{"version":255,"checksum":123456789012345678901234567890}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Version != 255 || p.Checksum != "123456789012345678901234567890" {
+		t.Errorf("unexpected values: %+v", p)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := json.Unmarshal([]byte(`{"version":256,"checksum":1}`), &p); err == nil {
+		t.Error("expected a version which doesn't fit in a uint8 to be rejected")
+	}
+
+	p = integers.Packet{Checksum: "1e31", Delta: 40000}
+	err := p.Validate()
+	expected := "/checksum: must be less than or equal to 1000000000000000000000000000000; /delta: must be less than 40000"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestThatArraysOfSmallIntegersAreMarshalledAsNumbers(t *testing.T) {
+	p := integers.Packet{Version: 1, Checksum: "1", Flags: []uint16{1, 2, 3}, Size: 18446744073709551615}
+	b, err := json.Marshal(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roundTripped integers.Packet
+	if err := json.Unmarshal(b, &roundTripped); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", b, err)
+	}
+	if !reflect.DeepEqual(roundTripped.Flags, p.Flags) || roundTripped.Size != p.Size {
+		t.Errorf("expected %+v, got %+v from %s", p, roundTripped, b)
+	}
+}
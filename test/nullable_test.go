@@ -0,0 +1,79 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/nullable_gen"
+)
+
+func TestThatOptionalPrimitivesArePointers(t *testing.T) {
+	var s nullable.Settings
+	if err := json.Unmarshal([]byte(`{"retries":0,"enabled":false,"comment":null,"mood":null}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Retries == nil || *s.Retries != 0 || s.Enabled == nil || *s.Enabled || s.Name != nil || s.Comment != nil {
+		t.Errorf("unexpected values: %+v", s)
+	}
+	if err := s.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	b, err := json.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTripped := map[string]interface{}{}
+	if err := json.Unmarshal(b, &roundTripped); err != nil {
+		t.Fatal(err)
+	}
+	if roundTripped["retries"] != 0.0 || roundTripped["enabled"] != false {
+		t.Errorf("expected the zero values to be kept, got %s", b)
+	}
+	// missing properties which aren't nullable are omitted, rather than written as null
+	if _, ok := roundTripped["name"]; ok {
+		t.Errorf("expected the missing name to be omitted, got %s", b)
+	}
+	if _, ok := roundTripped["level"]; ok {
+		t.Errorf("expected the missing level to be omitted, got %s", b)
+	}
+	if _, ok := roundTripped["tags"]; ok {
+		t.Errorf("expected the missing tags to be omitted, got %s", b)
+	}
+	if _, ok := roundTripped["labels"]; ok {
+		t.Errorf("expected the missing labels to be omitted, got %s", b)
+	}
+	if v, ok := roundTripped["comment"]; !ok || v != nil {
+		t.Errorf("expected the comment to be null, got %s", b)
+	}
+	if v, ok := roundTripped["mood"]; !ok || v != nil {
+		t.Errorf("expected the mood to be null, got %s", b)
+	}
+}
+
+func TestThatNullableEnumsAreStringEnums(t *testing.T) {
+	var s nullable.Settings
+	if err := json.Unmarshal([]byte(`{"comment":null,"mood":"happy"}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	var mood *nullable.Mood = s.Mood
+	if mood == nil || *mood != nullable.MoodHappy || string(nullable.MoodSad) != "sad" {
+		t.Errorf("expected the mood to be happy, got %v", mood)
+	}
+	if err := json.Unmarshal([]byte(`{"comment":null,"mood":"angry"}`), &s); err == nil {
+		t.Error("expected the invalid mood to be rejected")
+	}
+}
+
+func TestThatPointersAreValidatedWhenSet(t *testing.T) {
+	var s nullable.Settings
+	if err := json.Unmarshal([]byte(`{"retries":-1,"name":"","level":"medium","comment":"too long"}`), &s); err == nil {
+		t.Fatal("expected the invalid level to be rejected")
+	}
+	s.Level = nil
+	err := s.Validate()
+	expected := "/comment: must be at most 5 characters long; /name: must be at least 1 characters long; /retries: must be greater than or equal to 0"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
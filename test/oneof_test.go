@@ -0,0 +1,73 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/oneof_gen"
+)
+
+func TestOneOfWithConstDiscriminator(t *testing.T) {
+	e := &oneof.Event{}
+	if err := json.Unmarshal([]byte(`{"payload":{"kind":"deleted","reason":"spam"}}`), e); err != nil {
+		t.Fatal(err)
+	}
+	d, ok := e.Payload.Value.(*oneof.Deleted)
+	if !ok {
+		t.Fatalf("expected the payload to be *Deleted, got %T", e.Payload.Value)
+	}
+	if d.Reason != "spam" {
+		t.Errorf("expected reason 'spam', got '%s'", d.Reason)
+	}
+	if err := json.Unmarshal([]byte(`{"payload":{"kind":"updated"}}`), e); err == nil {
+		t.Error("expected an error for an unknown discriminator value")
+	}
+}
+
+func TestOneOfWithOpenAPIDiscriminator(t *testing.T) {
+	e := &oneof.Event{}
+	if err := json.Unmarshal([]byte(`{"pet":{"petType":"kitty","lives":9}}`), e); err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := e.Pet.Value.(*oneof.Cat); !ok || c.Lives != 9 {
+		t.Fatalf("expected a cat with 9 lives, got %#v", e.Pet.Value)
+	}
+	if err := json.Unmarshal([]byte(`{"pet":{"petType":"dog","breed":"collie"}}`), e); err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := e.Pet.Value.(*oneof.Dog); !ok || d.Breed != "collie" {
+		t.Fatalf("expected a collie, got %#v", e.Pet.Value)
+	}
+}
+
+func TestAnyOfWithoutDiscriminator(t *testing.T) {
+	e := &oneof.Event{}
+	if err := json.Unmarshal([]byte(`{"id":"abc"}`), e); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := e.Id.Value.(oneof.IdString); !ok || v != "abc" {
+		t.Errorf("expected the string 'abc', got %#v", e.Id.Value)
+	}
+	if err := json.Unmarshal([]byte(`{"id":12}`), e); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := e.Id.Value.(oneof.IdInt); !ok || v != 12 {
+		t.Errorf("expected the integer 12, got %#v", e.Id.Value)
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"id":12}` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
+
+func TestOneOfWithNullIsNotAUnion(t *testing.T) {
+	label := "thats the test"
+	e := oneof.Event{Label: &label}
+	if e.Label == nil || *e.Label != label {
+		t.Fatal("expected a string")
+	}
+}
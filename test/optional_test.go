@@ -0,0 +1,46 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/optional_gen"
+	"github.com/a-h/generate/types"
+)
+
+func TestThatOptionalPrimitivesUseTheGenericType(t *testing.T) {
+	var s optional.Settings
+	if err := json.Unmarshal([]byte(`{"retries":0,"level":"low","comment":null}`), &s); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := s.Retries.Get(); !ok || v != 0 {
+		t.Errorf("expected retries to be set to 0, got %v, %v", v, ok)
+	}
+	if v, ok := s.Level.Get(); !ok || v != optional.LevelLow {
+		t.Errorf("expected level to be set to low, got %v, %v", v, ok)
+	}
+	if s.Name.IsSet() || s.Enabled.IsSet() || s.Comment.IsSet() {
+		t.Errorf("unexpected values: %+v", s)
+	}
+
+	b, err := json.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"comment":null,"level":"low","nothing":null,"retries":0}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+}
+
+func TestThatOptionalValuesAreValidatedWhenSet(t *testing.T) {
+	s := optional.Settings{
+		Retries: types.Some(-1),
+		Comment: types.Some("too long"),
+	}
+	err := s.Validate()
+	expected := "/comment: must be at most 5 characters long; /retries: must be greater than or equal to 0"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+}
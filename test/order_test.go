@@ -0,0 +1,46 @@
+package test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/a-h/generate/test/order_gen"
+)
+
+func TestThatFieldsAreDeclaredInSchemaOrder(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected []string
+	}{
+		{value: order.Invoice{}, expected: []string{"Number", "Issued", "Customer", "Amount"}},
+		{value: order.Party{}, expected: []string{"Name", "Address", "VatNumber"}},
+	}
+	for _, test := range tests {
+		typ := reflect.TypeOf(test.value)
+		var actual []string
+		for i := 0; i < typ.NumField(); i++ {
+			actual = append(actual, typ.Field(i).Name)
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected fields %v, got %v", typ.Name(), test.expected, actual)
+		}
+	}
+}
+
+func TestThatFieldsAreMarshalledInSchemaOrder(t *testing.T) {
+	i := &order.Invoice{
+		Number:   "INV-1",
+		Issued:   "2018-03-04",
+		Customer: &order.Party{Name: "A", Address: "B", VatNumber: "C"},
+		Amount:   12.5,
+	}
+	b, err := json.Marshal(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"number":"INV-1","issued":"2018-03-04","customer":{"name":"A","address":"B","vatNumber":"C"},"amount":12.5}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, string(b))
+	}
+}
@@ -0,0 +1,60 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/patch_gen"
+	"github.com/a-h/generate/types"
+)
+
+func TestThatMissingNullAndSetValuesAreToldApart(t *testing.T) {
+	var p patch.UserPatch
+	if err := json.Unmarshal([]byte(`{"id":"1","name":"","nickname":null,"address":null}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Id != "1" {
+		t.Errorf("expected the id to be set, got %q", p.Id)
+	}
+	if v, ok := p.Name.Get(); !ok || v != "" {
+		t.Errorf("expected the name to be set to the empty string, got %+v", p.Name)
+	}
+	if !p.Nickname.IsNull() || !p.Address.IsNull() {
+		t.Errorf("expected the nickname and address to be null, got %+v and %+v", p.Nickname, p.Address)
+	}
+	if p.Age.IsPresent() || p.Tags.IsPresent() {
+		t.Errorf("expected the age and tags to be missing, got %+v and %+v", p.Age, p.Tags)
+	}
+
+	b, err := json.Marshal(&p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"address":null,"id":"1","name":"","nickname":null}`
+	if string(b) != expected {
+		t.Errorf("expected %s, got %s", expected, b)
+	}
+}
+
+func TestThatNullableValuesAreValidatedWhenSet(t *testing.T) {
+	p := patch.UserPatch{
+		Id:   "1",
+		Name: types.Value(""),
+		Age:  types.Value(0),
+		Tags: types.Value([]string{"a", "b", "c"}),
+	}
+	err := p.Validate()
+	expected := "/name: must be at least 1 characters long; /tags: must have at most 2 items"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q, got %v", expected, err)
+	}
+
+	p = patch.UserPatch{Id: "1", Age: types.Value(0), Name: types.Null[string]()}
+	if err := p.Validate(); err != nil {
+		t.Errorf("expected a null name to satisfy the dependency, got %v", err)
+	}
+	p.Name.Clear()
+	if err := p.Validate(); err == nil {
+		t.Error("expected the missing name to be reported")
+	}
+}
@@ -0,0 +1,19 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/a-h/generate/test/pointers_gen"
+)
+
+func TestThatReferencesToAnyJSONPointerAreResolved(t *testing.T) {
+	var p pointers.Pointers
+	data := `{"base":{"id":"a"},"choice":2,"pattern":{"value":"b"},"slash":"c","tilde":true,"space":1.5,"nested":{"n":3}}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Base.Id != "a" || p.Choice != 2 || p.Pattern.Value != "b" || p.Slash != "c" || !p.Tilde || p.Space != 1.5 || p.Nested.N != 3 {
+		t.Errorf("unexpected values: %+v", p)
+	}
+}
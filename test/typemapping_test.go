@@ -0,0 +1,42 @@
+package test
+
+import (
+	"encoding/json"
+	"go/types"
+	"testing"
+	"time"
+
+	"github.com/a-h/generate/test/typemapping_gen"
+)
+
+func TestThatTypeMappingsAreUsed(t *testing.T) {
+	data := `{"total":12345678901234567890.01,"rate":0.2,"kind":2,"created":"2018-03-04T05:06:07Z","parameters":{"a":["1","2"]},"lines":[1.10]}`
+	i := typemapping.Invoice{}
+	if err := json.Unmarshal([]byte(data), &i); err != nil {
+		t.Fatal(err)
+	}
+	// the value would lose precision as a float64
+	if i.Total != json.Number("12345678901234567890.01") {
+		t.Errorf("unexpected total: %v", i.Total)
+	}
+	if i.Rate != 0.2 {
+		t.Errorf("unexpected rate: %v", i.Rate)
+	}
+	if !i.Created.Equal(time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Errorf("unexpected created: %v", i.Created)
+	}
+	if i.Parameters.Get("a") != "1" || len(i.Parameters["a"]) != 2 {
+		t.Errorf("unexpected parameters: %v", i.Parameters)
+	}
+	if len(i.Lines) != 1 || i.Lines[0] != json.Number("1.10") {
+		t.Errorf("unexpected lines: %v", i.Lines)
+	}
+}
+
+func TestThatMappedPackagesAreRenamedWhenTheyClash(t *testing.T) {
+	// the mapped go/types package has the same name as the package holding types.ValidationErrors
+	i := typemapping.Invoice{Kind: types.Int, Lines: []json.Number{}}
+	if err := i.Validate(); err == nil || err.Error() != "/lines: must have at least 1 items" {
+		t.Errorf("expected an error for the missing lines, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/a-h/generate/test/validation_gen"
+	"github.com/a-h/generate/types"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			data: `{"reference":"ABC","quantity":10,"discount":0.5,"tags":["a","b"],"status":"open","version":2,
+				"lines":[{"sku":"x"}],"attributes":{"a":1},"customer":{"name":"Tim"}}`,
+		},
+		{
+			name:          "optional fields can be absent",
+			data:          `{"reference":"ABC","customer":{}}`,
+			expectedError: "",
+		},
+		{
+			name:          "string too short",
+			data:          `{"reference":"AB","customer":{}}`,
+			expectedError: "/reference: must be at least 3 characters long",
+		},
+		{
+			name:          "string too long",
+			data:          `{"reference":"ABCDEFGHI","customer":{}}`,
+			expectedError: "/reference: must be at most 8 characters long",
+		},
+		{
+			name:          "pattern",
+			data:          `{"reference":"abc","customer":{}}`,
+			expectedError: `/reference: must match the pattern "^[A-Z]+$"`,
+		},
+		{
+			name:          "minimum",
+			data:          `{"reference":"ABC","quantity":-5,"customer":{}}`,
+			expectedError: "/quantity: must be greater than or equal to 1",
+		},
+		{
+			name:          "multipleOf",
+			data:          `{"reference":"ABC","quantity":7,"customer":{}}`,
+			expectedError: "/quantity: must be a multiple of 5",
+		},
+		{
+			name:          "exclusiveMaximum",
+			data:          `{"reference":"ABC","discount":1,"customer":{}}`,
+			expectedError: "/discount: must be less than 1",
+		},
+		{
+			name:          "maxItems",
+			data:          `{"reference":"ABC","tags":["a","b","c","d"],"customer":{}}`,
+			expectedError: "/tags: must have at most 3 items",
+		},
+		{
+			name:          "uniqueItems",
+			data:          `{"reference":"ABC","tags":["a","a"],"customer":{}}`,
+			expectedError: "/tags: items must be unique",
+		},
+		{
+			name:          "array items",
+			data:          `{"reference":"ABC","tags":["abcdef"],"customer":{}}`,
+			expectedError: "/tags/0: must be at most 5 characters long",
+		},
+		{
+			name:          "const",
+			data:          `{"reference":"ABC","version":3,"customer":{}}`,
+			expectedError: "/version: must be 2",
+		},
+		{
+			name:          "nested structs in slices",
+			data:          `{"reference":"ABC","lines":[{"sku":"a"},{"sku":""}],"customer":{}}`,
+			expectedError: "/lines/1/sku: must be at least 1 characters long",
+		},
+		{
+			name:          "maps",
+			data:          `{"reference":"ABC","attributes":{"a":-1},"customer":{}}`,
+			expectedError: `/attributes/a: must be greater than or equal to 0`,
+		},
+		{
+			name:          "maxProperties",
+			data:          `{"reference":"ABC","attributes":{"a":1,"b":2,"c":3},"customer":{}}`,
+			expectedError: "/attributes: must have at most 2 properties",
+		},
+		{
+			name:          "nested pointers",
+			data:          `{"reference":"ABC","customer":{"name":""}}`,
+			expectedError: "",
+		},
+	}
+
+	for _, test := range tests {
+		o := &validation.Order{}
+		if err := json.Unmarshal([]byte(test.data), o); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		err := o.Validate()
+		if test.expectedError == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("%s: expected error %q, got %v", test.name, test.expectedError, err)
+		}
+	}
+}
+
+func TestValidateRequiredFields(t *testing.T) {
+	o := &validation.Order{Reference: "ABC"}
+	if err := o.Validate(); err == nil || err.Error() != `"customer" is required but was not present` {
+		t.Errorf("expected an error for the missing customer, got %v", err)
+	}
+}
+
+func TestValidateEnums(t *testing.T) {
+	o := &validation.Order{Reference: "ABC", Customer: &validation.Customer{}, Status: validation.Status("pending")}
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "pending is not a permitted Status value") {
+		t.Errorf("expected an error for the invalid status, got %v", err)
+	}
+}
+
+func TestThatAllUnmarshalErrorsAreReported(t *testing.T) {
+	data := `{"quantity":"ten","tags":["a",1,2],"lines":[{"sku":"a"},{}],"status":"pending","extra":true}`
+	err := json.Unmarshal([]byte(data), &validation.Order{})
+	errs, ok := err.(types.ValidationErrors)
+	if !ok {
+		t.Fatalf("expected types.ValidationErrors, got %T: %v", err, err)
+	}
+	expected := []struct {
+		instancePath string
+		schemaPath   string
+		keyword      string
+	}{
+		{instancePath: "", schemaPath: "#/required", keyword: "required"},
+		{instancePath: "", schemaPath: "#/required", keyword: "required"},
+		{instancePath: "/lines/1", schemaPath: "#/definitions/line/required", keyword: "required"},
+		{instancePath: "/quantity", keyword: "type"},
+		{instancePath: "/status", schemaPath: "#/properties/status/enum", keyword: "enum"},
+		{instancePath: "/tags/1", keyword: "type"},
+		{instancePath: "/tags/2", keyword: "type"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, e := range expected {
+		if errs[i].InstancePath != e.instancePath || errs[i].SchemaPath != e.schemaPath || errs[i].Keyword != e.keyword {
+			t.Errorf("error %d: expected %+v, got %+v", i, e, errs[i])
+		}
+	}
+}
+
+func TestThatAllValidationErrorsAreReported(t *testing.T) {
+	o := &validation.Order{
+		Reference: "ab",
+		Tags:      []string{"abcdef", "abcdef"},
+		Lines:     []*validation.Line{{Sku: "a"}, {Sku: ""}},
+	}
+	err := o.Validate()
+	errs, ok := err.(types.ValidationErrors)
+	if !ok {
+		t.Fatalf("expected types.ValidationErrors, got %T: %v", err, err)
+	}
+	expected := []types.ValidationError{
+		{InstancePath: "", SchemaPath: "#/required", Keyword: "required", Message: `"customer" is required but was not present`},
+		{InstancePath: "/lines/1/sku", SchemaPath: "#/definitions/line/properties/sku/minLength", Keyword: "minLength", Message: "must be at least 1 characters long"},
+		{InstancePath: "/reference", SchemaPath: "#/properties/reference/minLength", Keyword: "minLength", Message: "must be at least 3 characters long"},
+		{InstancePath: "/reference", SchemaPath: "#/properties/reference/pattern", Keyword: "pattern", Message: `must match the pattern "^[A-Z]+$"`},
+		{InstancePath: "/tags", SchemaPath: "#/properties/tags/uniqueItems", Keyword: "uniqueItems", Message: "items must be unique"},
+		{InstancePath: "/tags/0", SchemaPath: "#/properties/tags/items/maxLength", Keyword: "maxLength", Message: "must be at most 5 characters long"},
+		{InstancePath: "/tags/1", SchemaPath: "#/properties/tags/items/maxLength", Keyword: "maxLength", Message: "must be at most 5 characters long"},
+	}
+	if !reflect.DeepEqual(errs, types.ValidationErrors(expected)) {
+		t.Errorf("expected %+v, got %+v", expected, errs)
+	}
+}
@@ -0,0 +1,184 @@
+package generate
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TypeMapping overrides the golang type generated for the schemas that it matches. A schema can also set its own
+// golang type with the "x-go-type" keyword, which takes precedence over any mappings.
+type TypeMapping struct {
+	// Type and Format match schemas by their "type" and "format" keywords, e.g. "number" and "decimal". When Format
+	// is empty, schemas of the type match whatever their format is, unless another mapping matches the format.
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+	// Path matches a single schema by its $id, or by a JSON Pointer, e.g. "#/definitions/money" or
+	// "https://example.com/order.json#/definitions/money". Mappings with a Path take precedence over those without.
+	Path string `json:"path,omitempty"`
+	// GoType is the golang type, qualified by the import path of its package, e.g.
+	// "github.com/shopspring/decimal.Decimal" or "[]*github.com/example/money.Amount". Builtin types, e.g. "int64",
+	// aren't qualified.
+	GoType string `json:"goType"`
+}
+
+// ReadTypeMappings reads a JSON file containing an array of type mappings, e.g.
+// [{"type": "number", "goType": "github.com/shopspring/decimal.Decimal"}].
+func ReadTypeMappings(file string) ([]TypeMapping, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.New("failed to read the type mappings file with error " + err.Error())
+	}
+	var mappings []TypeMapping
+	if err := json.Unmarshal(b, &mappings); err != nil {
+		return nil, errors.New("failed to parse the type mappings file " + file + " with error " + err.Error())
+	}
+	for _, m := range mappings {
+		if m.GoType == "" || (m.Type == "" && m.Path == "") {
+			return nil, errors.New("type mappings in " + file + " must have a goType, and either a type or a path")
+		}
+	}
+	return mappings, nil
+}
+
+// getMappedTypeName returns the golang type set for the schema by its "x-go-type" keyword or the TypeMappings, and
+// adds the import that it needs.
+func (g *Generator) getMappedTypeName(schema *Schema) (typ string, ok bool, err error) {
+	goType := ""
+	if v, hasGoType := schema.Extensions["x-go-type"]; hasGoType {
+		s, isString := v.(string)
+		if !isString {
+			return "", false, errors.New("x-go-type must be a string at \"" + g.resolver.GetPath(schema) + "\"")
+		}
+		goType = s
+	} else if m, found := g.findTypeMapping(schema); found {
+		goType = m.GoType
+	}
+	if goType == "" {
+		return "", false, nil
+	}
+	typ, importPath, err := parseGoType(goType)
+	if err != nil {
+		return "", false, errors.New(err.Error() + " at \"" + g.resolver.GetPath(schema) + "\"")
+	}
+	if importPath != "" {
+		g.imports[importPath] = true
+		typ = g.renamePackage(typ, importPath)
+	}
+	return typ, true, nil
+}
+
+// generatedPackages are the packages that the generated code may import itself; k=name v=import path
+var generatedPackages = map[string]string{
+	"big":     "math/big",
+	"bytes":   "bytes",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"json":    "encoding/json",
+	"math":    "math",
+	"net":     "net",
+	"regexp":  "regexp",
+	"strconv": "strconv",
+	"time":    "time",
+	"types":   typesImport,
+	"utf8":    "unicode/utf8",
+}
+
+// renamePackage returns the type qualified by a name for its package which doesn't clash with any other package, e.g.
+// "types2.Amount" for "types.Amount" from "github.com/example/types", which would clash with the package holding
+// types.ValidationErrors. The name is imported explicitly when it's different to the package's own.
+func (g *Generator) renamePackage(typ string, importPath string) string {
+	qualified := strings.TrimLeft(typ, "[]*")
+	pkg := qualified[:strings.Index(qualified, ".")]
+	name, ok := g.importNames[importPath]
+	if !ok {
+		name = pkg
+		for i := 2; ; i++ {
+			generated, isGenerated := generatedPackages[name]
+			mapped, isMapped := g.mappedPackages[name]
+			if (!isGenerated || generated == importPath) && (!isMapped || mapped == importPath) {
+				break
+			}
+			name = pkg + strconv.Itoa(i)
+		}
+		g.mappedPackages[name] = importPath
+		if name != pkg {
+			g.importNames[importPath] = name
+		}
+	}
+	return typ[:len(typ)-len(qualified)] + name + qualified[len(pkg):]
+}
+
+func (g *Generator) findTypeMapping(schema *Schema) (TypeMapping, bool) {
+	pointer := g.resolver.GetPath(schema)
+	paths := []string{pointer, strings.TrimSuffix(schema.GetRoot().ID(), "#") + pointer}
+	if id := schema.ID(); id != "" {
+		paths = append(paths, id)
+	}
+	for _, m := range g.TypeMappings {
+		if m.Path != "" && contains(paths, m.Path) {
+			return m, true
+		}
+	}
+
+	schemaType, multiple := schema.Type()
+	if schemaType == "" || multiple {
+		return TypeMapping{}, false
+	}
+	var anyFormat *TypeMapping
+	for i, m := range g.TypeMappings {
+		if m.Path != "" || m.Type != schemaType {
+			continue
+		}
+		if m.Format == schema.Format {
+			return m, true
+		}
+		if m.Format == "" && anyFormat == nil {
+			anyFormat = &g.TypeMappings[i]
+		}
+	}
+	if anyFormat != nil {
+		return *anyFormat, true
+	}
+	return TypeMapping{}, false
+}
+
+var (
+	goIdentifier   = regexp.MustCompile(`^[\pL_][\pL\pN_]*$`)
+	versionSuffix  = regexp.MustCompile(`^v[0-9]+$`)
+	gopkgInVersion = regexp.MustCompile(`\.v[0-9]+$`)
+)
+
+// parseGoType splits a golang type qualified by the import path of its package, e.g.
+// "[]*github.com/shopspring/decimal.Decimal", into the type used by the generated code, e.g. "[]*decimal.Decimal",
+// and the import path, e.g. "github.com/shopspring/decimal". The package name is taken to be the last element of the
+// import path, ignoring any major version suffix, e.g. "/v2".
+func parseGoType(goType string) (typ string, importPath string, err error) {
+	name := strings.TrimLeft(goType, "[]*")
+	prefix := goType[:len(goType)-len(name)]
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		if !goIdentifier.MatchString(name) {
+			return "", "", errors.New("invalid golang type \"" + goType + "\"")
+		}
+		return goType, "", nil
+	}
+	importPath, name = name[:dot], name[dot+1:]
+	if !goIdentifier.MatchString(name) || strings.HasSuffix(importPath, "/") {
+		return "", "", errors.New("invalid golang type \"" + goType + "\"")
+	}
+	pkg := path.Base(importPath)
+	if versionSuffix.MatchString(pkg) && strings.Contains(importPath, "/") {
+		pkg = path.Base(path.Dir(importPath))
+	}
+	pkg = gopkgInVersion.ReplaceAllString(pkg, "")
+	pkg = strings.Replace(strings.TrimPrefix(pkg, "go-"), "-", "", -1)
+	if !goIdentifier.MatchString(pkg) {
+		return "", "", errors.New("cannot determine the package name of golang type \"" + goType + "\"")
+	}
+	return prefix + pkg + "." + name, importPath, nil
+}
@@ -0,0 +1,121 @@
+package generate
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestThatGoTypesAreSplitIntoTypeAndImportPath(t *testing.T) {
+	tests := []struct {
+		goType             string
+		expectedType       string
+		expectedImportPath string
+		expectedError      bool
+	}{
+		{goType: "int64", expectedType: "int64"},
+		{goType: "github.com/shopspring/decimal.Decimal", expectedType: "decimal.Decimal", expectedImportPath: "github.com/shopspring/decimal"},
+		{goType: "[]*github.com/shopspring/decimal.Decimal", expectedType: "[]*decimal.Decimal", expectedImportPath: "github.com/shopspring/decimal"},
+		{goType: "time.Time", expectedType: "time.Time", expectedImportPath: "time"},
+		{goType: "github.com/example/money/v2.Amount", expectedType: "money.Amount", expectedImportPath: "github.com/example/money/v2"},
+		{goType: "gopkg.in/yaml.v2.Node", expectedType: "yaml.Node", expectedImportPath: "gopkg.in/yaml.v2"},
+		{goType: "github.com/example/go-money.Amount", expectedType: "money.Amount", expectedImportPath: "github.com/example/go-money"},
+		{goType: "github.com/example/money.", expectedError: true},
+		{goType: "not a type", expectedError: true},
+	}
+
+	for _, test := range tests {
+		typ, importPath, err := parseGoType(test.goType)
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.goType)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.goType, err)
+			continue
+		}
+		if typ != test.expectedType || importPath != test.expectedImportPath {
+			t.Errorf("%s: expected %s from %q, got %s from %q", test.goType, test.expectedType, test.expectedImportPath, typ, importPath)
+		}
+	}
+}
+
+func TestThatTypeMappingsArePrioritised(t *testing.T) {
+	s := `{
+        "$schema": "http://json-schema.org/draft-07/schema#",
+        "$id": "https://example.com/order.json",
+        "title": "Order",
+        "properties": {
+            "total": { "type": "number" },
+            "tax": { "type": "number", "format": "double" },
+            "discount": { "$ref": "#/definitions/discount" },
+            "fee": { "type": "number", "x-go-type": "github.com/example/money.Amount" },
+            "count": { "type": "integer" }
+        },
+        "definitions": {
+            "discount": { "type": "number" }
+        }
+    }`
+	root, err := Parse(s, &url.URL{Scheme: "file", Path: "typemapping_test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New(root)
+	g.TypeMappings = []TypeMapping{
+		{Type: "number", GoType: "github.com/shopspring/decimal.Decimal"},
+		{Type: "number", Format: "double", GoType: "float64"},
+		{Path: "https://example.com/order.json#/definitions/discount", GoType: "github.com/example/money.Percentage"},
+	}
+	if err := g.CreateTypes(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"Total":    "decimal.Decimal",
+		"Tax":      "float64",
+		"Discount": "money.Percentage",
+		"Fee":      "money.Amount",
+		"Count":    "int",
+	}
+	fields := g.Structs["Order"].Fields
+	for name, typ := range expected {
+		if fields[name].Type != typ {
+			t.Errorf("expected %s to be %s, got %s", name, typ, fields[name].Type)
+		}
+	}
+	for _, importPath := range []string{"github.com/shopspring/decimal", "github.com/example/money"} {
+		if !g.imports[importPath] {
+			t.Errorf("expected %s to be imported", importPath)
+		}
+	}
+	if _, ok := g.Structs["Discount"]; ok || len(g.Aliases) != 0 {
+		t.Error("expected no types to be generated for the mapped definition")
+	}
+}
+
+func TestThatClashingPackagesAreRenamed(t *testing.T) {
+	g := New()
+	tests := []struct {
+		typ        string
+		importPath string
+		expected   string
+	}{
+		{typ: "money.Amount", importPath: "github.com/a/money", expected: "money.Amount"},
+		{typ: "[]*money.Amount", importPath: "github.com/b/money", expected: "[]*money2.Amount"},
+		{typ: "money.Rate", importPath: "github.com/a/money", expected: "money.Rate"},
+		{typ: "types.Kind", importPath: "github.com/example/types", expected: "types2.Kind"},
+		{typ: "types.Date", importPath: typesImport, expected: "types.Date"},
+		{typ: "json.Number", importPath: "encoding/json", expected: "json.Number"},
+	}
+	for _, test := range tests {
+		if actual := g.renamePackage(test.typ, test.importPath); actual != test.expected {
+			t.Errorf("%s from %s: expected %s, got %s", test.typ, test.importPath, test.expected, actual)
+		}
+	}
+	expected := map[string]string{"github.com/b/money": "money2", "github.com/example/types": "types2"}
+	if !reflect.DeepEqual(g.importNames, expected) {
+		t.Errorf("expected the import names %v, got %v", expected, g.importNames)
+	}
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestThatDatesCanBeParsed(t *testing.T) {
+	d, err := ParseDate("2018-02-28")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != (Date{Year: 2018, Month: time.February, Day: 28}) {
+		t.Errorf("unexpected date: %+v", d)
+	}
+	if d.String() != "2018-02-28" {
+		t.Errorf("unexpected string: %s", d)
+	}
+	for _, invalid := range []string{"2018-02-30", "2018-2-28", "2018-02-28T00:00:00Z"} {
+		if _, err := ParseDate(invalid); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestThatDatesCanBeMarshalled(t *testing.T) {
+	var v struct {
+		Date Date
+		Zero Date
+	}
+	if err := json.Unmarshal([]byte(`{"Date":"0001-01-02","Zero":""}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"Date":"0001-01-02","Zero":""}` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
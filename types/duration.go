@@ -0,0 +1,129 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is an ISO 8601 duration, as used by the "duration" format, e.g. "P1Y2M10DT2H30M". Years, months and
+// days don't have a fixed length, so they're held separately from the time component. Weeks are held as days.
+type Duration struct {
+	Years  int
+	Months int
+	Days   int
+	// Time is the length of the time component, i.e. the hours, minutes and seconds.
+	Time time.Duration
+}
+
+// ParseDuration parses a duration in the ISO 8601 format, e.g. "P1Y2M10DT2H30M", "P3W" or "PT0.5S".
+func ParseDuration(s string) (Duration, error) {
+	invalid := errors.New("invalid duration: \"" + s + "\"")
+	var d Duration
+	if !strings.HasPrefix(s, "P") || s == "P" || strings.HasSuffix(s, "T") {
+		return d, invalid
+	}
+	rest := s[1:]
+	inTime := false
+	units := "YMWD"
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return d, invalid
+			}
+			inTime, units, rest = true, "HMS", rest[1:]
+			continue
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != ',' })
+		if end <= 0 {
+			return d, invalid
+		}
+		number, unit := strings.Replace(rest[:end], ",", ".", 1), rest[end]
+		rest = rest[end+1:]
+		// the units must appear in order, and only once
+		i := strings.IndexByte(units, unit)
+		if i < 0 {
+			return d, invalid
+		}
+		units = units[i+1:]
+		if inTime {
+			f, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return d, invalid
+			}
+			scale := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}[unit]
+			d.Time += time.Duration(f * float64(scale))
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return d, invalid
+		}
+		switch unit {
+		case 'Y':
+			d.Years = n
+		case 'M':
+			d.Months = n
+		case 'W':
+			d.Days += 7 * n
+		case 'D':
+			d.Days += n
+		}
+	}
+	return d, nil
+}
+
+// IsZero returns true when the duration has no length.
+func (d Duration) IsZero() bool {
+	return d == Duration{}
+}
+
+func (d Duration) String() string {
+	if d.IsZero() {
+		return "PT0S"
+	}
+	sb := new(bytes.Buffer)
+	sb.WriteString("P")
+	for _, c := range []struct {
+		n    int
+		unit string
+	}{{d.Years, "Y"}, {d.Months, "M"}, {d.Days, "D"}} {
+		if c.n != 0 {
+			sb.WriteString(strconv.Itoa(c.n) + c.unit)
+		}
+	}
+	if d.Time == 0 {
+		return sb.String()
+	}
+	sb.WriteString("T")
+	t := d.Time
+	if h := t / time.Hour; h != 0 {
+		sb.WriteString(strconv.FormatInt(int64(h), 10) + "H")
+		t -= h * time.Hour
+	}
+	if m := t / time.Minute; m != 0 {
+		sb.WriteString(strconv.FormatInt(int64(m), 10) + "M")
+		t -= m * time.Minute
+	}
+	if t != 0 {
+		sb.WriteString(strconv.FormatFloat(t.Seconds(), 'f', -1, 64) + "S")
+	}
+	return sb.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestThatDurationsCanBeParsed(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Duration
+		output   string
+	}{
+		{input: "P1Y2M10DT2H30M", expected: Duration{Years: 1, Months: 2, Days: 10, Time: 2*time.Hour + 30*time.Minute}, output: "P1Y2M10DT2H30M"},
+		{input: "P3W", expected: Duration{Days: 21}, output: "P21D"},
+		{input: "PT0.5S", expected: Duration{Time: 500 * time.Millisecond}, output: "PT0.5S"},
+		{input: "PT1,5M", expected: Duration{Time: 90 * time.Second}, output: "PT1M30S"},
+		{input: "PT0S", expected: Duration{}, output: "PT0S"},
+		{input: "P1M", expected: Duration{Months: 1}, output: "P1M"},
+		{input: "PT1M", expected: Duration{Time: time.Minute}, output: "PT1M"},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseDuration(test.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.input, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.input, test.expected, actual)
+		}
+		if actual.String() != test.output {
+			t.Errorf("%s: expected %s, got %s", test.input, test.output, actual)
+		}
+	}
+}
+
+func TestThatInvalidDurationsAreRejected(t *testing.T) {
+	for _, invalid := range []string{"", "P", "1D", "PT", "P1DT", "P1H", "PT1D", "P1D1Y", "P1Y1Y", "PT1HT1M", "P1.5Y"} {
+		if d, err := ParseDuration(invalid); err == nil {
+			t.Errorf("%q: expected an error, got %+v", invalid, d)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package types contains the types used by the code that schema-generate produces.
+package types
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// ValidationError describes a value which doesn't satisfy the JSON schema.
+type ValidationError struct {
+	// InstancePath is a JSON Pointer to the invalid value, e.g. "/items/3/name".
+	InstancePath string
+	// SchemaPath is the location of the failing keyword within the schema, e.g. "#/properties/name/minLength".
+	SchemaPath string
+	// Keyword is the failing schema keyword, e.g. "minLength".
+	Keyword string
+	// Message describes the problem.
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.InstancePath == "" {
+		return e.Message
+	}
+	return e.InstancePath + ": " + e.Message
+}
+
+// ValidationErrors collects all of the problems found while unmarshalling or validating a value.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Err returns the errors ordered by instance path, or nil if there aren't any. Errors for the same instance path
+// keep the order in which they were added.
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	sort.SliceStable(e, func(i, j int) bool {
+		return e[i].InstancePath < e[j].InstancePath
+	})
+	return e
+}
+
+// Add records a problem with the value at the instance path.
+func (e *ValidationErrors) Add(instancePath, schemaPath, keyword, message string) {
+	*e = append(*e, ValidationError{
+		InstancePath: instancePath,
+		SchemaPath:   schemaPath,
+		Keyword:      keyword,
+		Message:      message,
+	})
+}
+
+// Merge records err, returned while unmarshalling or validating the value at the instance path. The paths of any
+// ValidationErrors it contains are relative to the instance path. Merge does nothing if err is nil.
+func (e *ValidationErrors) Merge(instancePath string, err error) {
+	switch err := err.(type) {
+	case nil:
+	case ValidationErrors:
+		for _, ve := range err {
+			ve.InstancePath = instancePath + ve.InstancePath
+			*e = append(*e, ve)
+		}
+	case ValidationError:
+		err.InstancePath = instancePath + err.InstancePath
+		*e = append(*e, err)
+	case *ValidationError:
+		e.Merge(instancePath, *err)
+	case *json.UnmarshalTypeError:
+		path := instancePath
+		if err.Field != "" {
+			for _, token := range strings.Split(err.Field, ".") {
+				path = JoinPointer(path, token)
+			}
+		}
+		e.Add(path, "", "type", "cannot unmarshal "+err.Value+" into a value of type "+err.Type.String())
+	default:
+		e.Add(instancePath, "", "", err.Error())
+	}
+}
+
+// JoinPointer appends a reference token to a JSON Pointer, escaping it as described in RFC 6901.
+func JoinPointer(pointer, token string) string {
+	token = strings.Replace(token, "~", "~0", -1)
+	token = strings.Replace(token, "/", "~1", -1)
+	return pointer + "/" + token
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestThatPointerTokensAreEscaped(t *testing.T) {
+	tests := []struct {
+		pointer  string
+		token    string
+		expected string
+	}{
+		{pointer: "", token: "name", expected: "/name"},
+		{pointer: "/items", token: "3", expected: "/items/3"},
+		{pointer: "", token: "a/b", expected: "/a~1b"},
+		{pointer: "", token: "m~n", expected: "/m~0n"},
+		{pointer: "", token: "", expected: "/"},
+	}
+
+	for _, test := range tests {
+		if actual := JoinPointer(test.pointer, test.token); actual != test.expected {
+			t.Errorf("JoinPointer(%q, %q): expected %q, got %q", test.pointer, test.token, test.expected, actual)
+		}
+	}
+}
+
+func TestThatMergedErrorsAreRelativeToTheInstancePath(t *testing.T) {
+	var errs ValidationErrors
+	errs.Merge("/a", nil)
+	errs.Merge("/items/0", ValidationErrors{{InstancePath: "/name", Keyword: "minLength"}})
+	errs.Merge("/status", ValidationError{Keyword: "enum"})
+	errs.Merge("/other", errors.New("failed"))
+
+	expected := ValidationErrors{
+		{InstancePath: "/items/0/name", Keyword: "minLength"},
+		{InstancePath: "/status", Keyword: "enum"},
+		{InstancePath: "/other", Message: "failed"},
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %+v, got %+v", expected, errs)
+	}
+}
+
+func TestThatErrIsNilWithoutErrors(t *testing.T) {
+	var errs ValidationErrors
+	if err := errs.Err(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+func TestThatErrorsAreOrderedByInstancePath(t *testing.T) {
+	var errs ValidationErrors
+	errs.Add("/b", "", "maxLength", "too long")
+	errs.Add("/a", "", "", "first")
+	errs.Add("/a", "", "", "second")
+
+	expected := "/a: first; /a: second; /b: too long"
+	if actual := errs.Err().Error(); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build go1.18
+// +build go1.18
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Nullable holds a value which may be missing, null or set, so that the three cases can be told apart when
+// unmarshalling, e.g. to implement JSON Merge Patch (RFC 7396), where null removes a value and a missing value leaves
+// it unchanged. Both a missing and a null Nullable are marshalled as null, so a missing one should be omitted.
+type Nullable[T any] struct {
+	value   T
+	present bool
+	null    bool
+}
+
+// Value returns a Nullable set to v.
+func Value[T any](v T) Nullable[T] {
+	return Nullable[T]{value: v, present: true}
+}
+
+// Null returns a Nullable set to null.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{present: true, null: true}
+}
+
+// Get returns the value, and whether it has been set to one, i.e. it isn't missing or null.
+func (n Nullable[T]) Get() (v T, ok bool) {
+	return n.value, n.present && !n.null
+}
+
+// IsPresent returns true when the value is null or has been set.
+func (n Nullable[T]) IsPresent() bool {
+	return n.present
+}
+
+// IsNull returns true when the value is null.
+func (n Nullable[T]) IsNull() bool {
+	return n.null
+}
+
+// IsZero returns true when the value is missing, so that fields tagged "omitzero" are omitted.
+func (n Nullable[T]) IsZero() bool {
+	return !n.present
+}
+
+// Set sets the value.
+func (n *Nullable[T]) Set(v T) {
+	*n = Value(v)
+}
+
+// SetNull sets the value to null.
+func (n *Nullable[T]) SetNull() {
+	*n = Null[T]()
+}
+
+// Clear makes the value missing.
+func (n *Nullable[T]) Clear() {
+	*n = Nullable[T]{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.present || n.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It's only called for values which are present.
+func (n *Nullable[T]) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		n.SetNull()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	n.Set(v)
+	return nil
+}
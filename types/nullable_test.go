@@ -0,0 +1,68 @@
+//go:build go1.18
+// +build go1.18
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestThatNullableValuesTellMissingNullAndSetApart(t *testing.T) {
+	var patch struct {
+		Missing Nullable[string] `json:"missing"`
+		Null    Nullable[string] `json:"null"`
+		Set     Nullable[string] `json:"set"`
+	}
+	if err := json.Unmarshal([]byte(`{"null":null,"set":""}`), &patch); err != nil {
+		t.Fatal(err)
+	}
+	if patch.Missing.IsPresent() || patch.Missing.IsNull() || !patch.Missing.IsZero() {
+		t.Errorf("expected the missing value to be missing, got %+v", patch.Missing)
+	}
+	if !patch.Null.IsPresent() || !patch.Null.IsNull() {
+		t.Errorf("expected the null value to be null, got %+v", patch.Null)
+	}
+	if _, ok := patch.Null.Get(); ok {
+		t.Error("expected the null value not to have a value")
+	}
+	if v, ok := patch.Set.Get(); !ok || v != "" || patch.Set.IsNull() {
+		t.Errorf("expected the set value to be the empty string, got %+v", patch.Set)
+	}
+}
+
+func TestThatNullableValuesCanBeMarshalled(t *testing.T) {
+	tests := []struct {
+		value    Nullable[int]
+		expected string
+	}{
+		{value: Nullable[int]{}, expected: "null"},
+		{value: Null[int](), expected: "null"},
+		{value: Value(0), expected: "0"},
+	}
+	for _, test := range tests {
+		b, err := json.Marshal(test.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, string(b))
+		}
+	}
+}
+
+func TestThatNullableValuesCanBeChanged(t *testing.T) {
+	var n Nullable[int]
+	n.Set(1)
+	if v, ok := n.Get(); !ok || v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+	n.SetNull()
+	if !n.IsNull() {
+		t.Error("expected null")
+	}
+	n.Clear()
+	if n.IsPresent() {
+		t.Error("expected the value to be missing")
+	}
+}
@@ -0,0 +1,69 @@
+//go:build go1.18
+// +build go1.18
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Optional holds a value which may not have been set, so that a zero value, e.g. 0 or "", can be told apart from a
+// missing one. An unset Optional is marshalled as null, and null is unmarshalled as an unset Optional.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Optional set to v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, set: true}
+}
+
+// Get returns the value, and whether it has been set.
+func (o Optional[T]) Get() (v T, ok bool) {
+	return o.value, o.set
+}
+
+// IsSet returns true when the value has been set.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsZero returns true when the value hasn't been set, so that fields tagged "omitzero" are omitted.
+func (o Optional[T]) IsZero() bool {
+	return !o.set
+}
+
+// Set sets the value.
+func (o *Optional[T]) Set(v T) {
+	o.value, o.set = v, true
+}
+
+// Clear unsets the value.
+func (o *Optional[T]) Clear() {
+	var zero T
+	o.value, o.set = zero, false
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		o.Clear()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	o.Set(v)
+	return nil
+}
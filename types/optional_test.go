@@ -0,0 +1,61 @@
+//go:build go1.18
+// +build go1.18
+
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestThatOptionalValuesCanBeRoundTripped(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Optional[int]
+	}{
+		{input: "0", expected: Some(0)},
+		{input: "12", expected: Some(12)},
+		{input: "null", expected: Optional[int]{}},
+	}
+	for _, test := range tests {
+		var actual Optional[int]
+		if err := json.Unmarshal([]byte(test.input), &actual); err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.input, err)
+		}
+		if actual != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.input, test.expected, actual)
+		}
+		b, err := json.Marshal(actual)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.input, err)
+		}
+		if string(b) != test.input {
+			t.Errorf("expected %s, got %s", test.input, string(b))
+		}
+	}
+}
+
+func TestThatOptionalValuesCanBeSetAndCleared(t *testing.T) {
+	var o Optional[string]
+	if _, ok := o.Get(); ok || o.IsSet() || !o.IsZero() {
+		t.Error("expected the zero Optional to be unset")
+	}
+	o.Set("")
+	if v, ok := o.Get(); !ok || v != "" {
+		t.Errorf("expected the empty string to be set, got %q, %v", v, ok)
+	}
+	o.Clear()
+	if o.IsSet() {
+		t.Error("expected the value to be cleared")
+	}
+}
+
+func TestThatInvalidOptionalValuesAreRejected(t *testing.T) {
+	var o Optional[int]
+	if err := json.Unmarshal([]byte(`"a"`), &o); err == nil {
+		t.Error("expected an error")
+	}
+	if o.IsSet() {
+		t.Error("expected the value to be unset")
+	}
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// Unmarshal parses the JSON-encoded data and stores the result in the value pointed to by v, in the same way as
+// json.Unmarshal. Arrays and objects are unmarshalled an element at a time, so that a ValidationError is returned
+// for every element which can't be unmarshalled, rather than just the first.
+func Unmarshal(data []byte, v interface{}) error {
+	var errs ValidationErrors
+	unmarshal(data, reflect.ValueOf(v).Elem(), "", &errs)
+	return errs.Err()
+}
+
+func unmarshal(data []byte, v reflect.Value, path string, errs *ValidationErrors) {
+	if reflect.PtrTo(v.Type()).Implements(unmarshalerType) {
+		errs.Merge(path, json.Unmarshal(data, v.Addr().Interface()))
+		return
+	}
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			errs.Merge(path, err)
+			return
+		}
+		if items == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			unmarshal(item, s.Index(i), path+"/"+strconv.Itoa(i), errs)
+		}
+		v.Set(s)
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		var items map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			errs.Merge(path, err)
+			return
+		}
+		if items == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		keys := make([]string, 0, len(items))
+		for k := range items {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		m := reflect.MakeMapWithSize(v.Type(), len(items))
+		for _, k := range keys {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			unmarshal(items[k], elem, JoinPointer(path, k), errs)
+			m.SetMapIndex(reflect.ValueOf(k).Convert(v.Type().Key()), elem)
+		}
+		v.Set(m)
+	default:
+		errs.Merge(path, json.Unmarshal(data, v.Addr().Interface()))
+	}
+}
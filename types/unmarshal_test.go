@@ -0,0 +1,46 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestThatElementsAreUnmarshalledIndividually(t *testing.T) {
+	var v struct {
+		Name string `json:"name"`
+	}
+	var values map[string][]int
+	err := Unmarshal([]byte(`{"a":[1,"2",3],"b/c":[true]}`), &values)
+
+	expected := ValidationErrors{
+		{InstancePath: "/a/1", Keyword: "type", Message: "cannot unmarshal string into a value of type int"},
+		{InstancePath: "/b~1c/0", Keyword: "type", Message: "cannot unmarshal bool into a value of type int"},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected %+v, got %+v", expected, err)
+	}
+	if !reflect.DeepEqual(values, map[string][]int{"a": {1, 0, 3}, "b/c": {0}}) {
+		t.Errorf("expected the valid elements to be unmarshalled, got %v", values)
+	}
+
+	err = Unmarshal([]byte(`{"name":1}`), &v)
+	if errs, ok := err.(ValidationErrors); !ok || len(errs) != 1 || errs[0].InstancePath != "/name" {
+		t.Errorf("expected an error for /name, got %v", err)
+	}
+}
+
+func TestThatUnmarshalMatchesTheStandardLibrary(t *testing.T) {
+	tests := []string{`null`, `[]`, `[1,2]`}
+
+	for _, test := range tests {
+		var expected, actual []int
+		json.Unmarshal([]byte(test), &expected)
+		if err := Unmarshal([]byte(test), &actual); err != nil {
+			t.Errorf("%s: unexpected error: %v", test, err)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected %#v, got %#v", test, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"net/url"
+)
+
+// URL is a URL which is marshalled as a string, as used by the "uri" and "uri-reference" formats.
+type URL struct {
+	url.URL
+}
+
+// ParseURL parses a URL in the "uri" or "uri-reference" format.
+func ParseURL(s string) (URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return URL{}, err
+	}
+	return URL{URL: *u}, nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.URL.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *URL) UnmarshalText(b []byte) error {
+	v, err := ParseURL(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestThatURLsCanBeMarshalled(t *testing.T) {
+	var v struct {
+		Link URL
+	}
+	if err := json.Unmarshal([]byte(`{"Link":"https://example.com/a?b=c#d"}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Link.Scheme != "https" || v.Link.Path != "/a" || v.Link.Fragment != "d" {
+		t.Errorf("unexpected URL: %+v", v.Link)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"Link":"https://example.com/a?b=c#d"}` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+}
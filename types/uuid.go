@@ -0,0 +1,52 @@
+package types
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// UUID is a universally unique identifier, as used by the "uuid" format, e.g.
+// "123e4567-e89b-12d3-a456-426614174000".
+type UUID [16]byte
+
+// ParseUUID parses a UUID in the "uuid" format. Hexadecimal digits may be upper or lower case.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, errors.New("invalid UUID: \"" + s + "\"")
+	}
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+	if _, err := hex.Decode(u[:], []byte(digits)); err != nil {
+		return UUID{}, errors.New("invalid UUID: \"" + s + "\"")
+	}
+	return u, nil
+}
+
+func (u UUID) String() string {
+	b := make([]byte, 36)
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (u *UUID) UnmarshalText(b []byte) error {
+	v, err := ParseUUID(string(b))
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
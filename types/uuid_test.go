@@ -0,0 +1,23 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestThatUUIDsCanBeParsed(t *testing.T) {
+	u, err := ParseUUID("123E4567-e89b-12d3-a456-426614174000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u[0] != 0x12 || u[15] != 0x00 || u[6] != 0x12 {
+		t.Errorf("unexpected bytes: %v", u)
+	}
+	if u.String() != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("unexpected string: %s", u)
+	}
+	for _, invalid := range []string{"", "123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g", "123e4567-e89b-12d3-a4564-26614174000"} {
+		if _, err := ParseUUID(invalid); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
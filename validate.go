@@ -0,0 +1,556 @@
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// validationEmitter writes the Validate methods of the generated types.
+type validationEmitter struct {
+	g       *Generator
+	imports map[string]bool
+	// regular expressions used by the generated code; k=pattern v=variable name
+	patterns map[string]string
+}
+
+func newValidationEmitter(g *Generator, imports map[string]bool) *validationEmitter {
+	return &validationEmitter{
+		g:        g,
+		imports:  imports,
+		patterns: make(map[string]string),
+	}
+}
+
+func (v *validationEmitter) emitStruct(w io.Writer, s Struct) {
+	v.imports[typesImport] = true
+	fmt.Fprintf(w, `
+// Validate returns a types.ValidationErrors listing each of the constraints of the JSON schema that the %[1]s
+// doesn't satisfy.
+func (strct *%[1]s) Validate() error {
+    if strct == nil {
+        return nil
+    }
+    var errs types.ValidationErrors
+`, s.Name)
+	for _, fieldKey := range getOrderedFieldNames(s.Fields) {
+		f := s.Fields[fieldKey]
+		expr := "strct." + f.Name
+		if f.JSONName == "-" {
+			// additional properties
+			if s.schema != nil && s.schema.AdditionalProperties != nil && s.schema.AdditionalProperties.AdditionalPropertiesBool == nil {
+				v.emitElements(w, expr, f.Type, (*Schema)(s.schema.AdditionalProperties), `""`, 0)
+			}
+			continue
+		}
+		// nil is a valid value for a nullable property
+		if f.Required && isNillable(f.Type) && (f.schema == nil || !f.schema.isNullable()) {
+			fmt.Fprintf(w, `    if %s == nil {
+        errs.Add("", %q, "required", %q)
+    }
+`, expr, v.g.schemaPath(s.schema)+"/required", requiredMessage(f.JSONName))
+		}
+		v.emitValue(w, expr, f.Type, f.schema, strconv.Quote(jsonPointer(f.JSONName)), f.Required, 0)
+		// generated types only validate the constraints of the schema they were generated from
+		for _, schema := range f.allOf {
+			if !v.hasValidate(strings.TrimPrefix(f.Type, "*")) {
+				v.emitValue(w, expr, f.Type, schema, strconv.Quote(jsonPointer(f.JSONName)), f.Required, 0)
+			}
+		}
+	}
+	v.emitDependentRequired(w, s)
+	fmt.Fprintf(w, "    return errs.Err()\n")
+	fmt.Fprintf(w, "}\n")
+}
+
+// emitDependentRequired writes checks that the properties which must be present alongside another are. A property
+// is considered to be present when its field doesn't hold the zero value.
+func (v *validationEmitter) emitDependentRequired(w io.Writer, s Struct) {
+	if s.schema == nil {
+		return
+	}
+	fields := make(map[string]Field, len(s.Fields))
+	for _, f := range s.Fields {
+		fields[f.JSONName] = f
+	}
+	keyword := "dependentRequired"
+	if s.schema.Draft() < Draft201909 {
+		keyword = "dependencies"
+	}
+	dependencies := s.schema.dependentRequiredProperties()
+	for _, property := range getOrderedStringSliceKeys(dependencies) {
+		f, ok := fields[property]
+		if !ok {
+			continue
+		}
+		for _, dependent := range dependencies[property] {
+			d, ok := fields[dependent]
+			if !ok || d.Required {
+				continue
+			}
+			fmt.Fprintf(w, `    if %s && !(%s) {
+        errs.Add("", %q, %q, %q)
+    }
+`, v.isPresent("strct."+f.Name, f.Type), v.isPresent("strct."+d.Name, d.Type),
+				v.g.schemaPath(s.schema)+"/"+keyword+"/"+property, keyword,
+				fmt.Sprintf("%q is required when %q is present", dependent, property))
+		}
+	}
+}
+
+// isPresent returns a golang condition which is true when expr, a field of type typ, holds a value.
+func (v *validationEmitter) isPresent(expr, typ string) string {
+	if isOptionalType(typ) {
+		return "!" + expr + ".IsZero()"
+	}
+	return expr + " != " + zeroValue(v.g.underlyingType(typ))
+}
+
+func (v *validationEmitter) emitTuple(w io.Writer, t Tuple) {
+	v.imports[typesImport] = true
+	fmt.Fprintf(w, `
+// Validate returns a types.ValidationErrors listing each of the constraints of the JSON schema that the %[1]s
+// doesn't satisfy.
+func (strct *%[1]s) Validate() error {
+    if strct == nil {
+        return nil
+    }
+    var errs types.ValidationErrors
+`, t.Name)
+	for _, f := range t.Items {
+		v.emitValue(w, "strct."+f.Name, f.Type, f.schema, strconv.Quote(jsonPointer(f.JSONName)), f.Required, 0)
+	}
+	if t.RestType != "" && t.schema != nil {
+		buf := new(bytes.Buffer)
+		v.emitValue(buf, "item0", t.RestType, t.schema.Items, fmt.Sprintf(`"/" + strconv.Itoa(%d+k0)`, len(t.Items)), true, 1)
+		if buf.Len() > 0 {
+			v.imports["strconv"] = true
+			fmt.Fprintf(w, "    for k0, item0 := range strct.Rest {\n")
+			w.Write(indent(buf.Bytes()))
+			fmt.Fprintf(w, "    }\n")
+		}
+	}
+	fmt.Fprintf(w, "    return errs.Err()\n")
+	fmt.Fprintf(w, "}\n")
+}
+
+func (v *validationEmitter) emitAlias(w io.Writer, a Field) {
+	v.imports[typesImport] = true
+	fmt.Fprintf(w, `
+// Validate returns a types.ValidationErrors listing each of the constraints of the JSON schema that the %[1]s
+// doesn't satisfy.
+func (v %[1]s) Validate() error {
+    var errs types.ValidationErrors
+`, a.Name)
+	v.emitValue(w, underlyingValue("v", a.Type), a.Type, a.schema, `""`, true, 0)
+	fmt.Fprintf(w, "    return errs.Err()\n")
+	fmt.Fprintf(w, "}\n")
+}
+
+func (v *validationEmitter) emitEnum(w io.Writer, e Enum) {
+	v.imports["fmt"] = true
+	v.imports[typesImport] = true
+	fmt.Fprintf(w, `
+// Validate returns a types.ValidationError if the %[1]s isn't one of the permitted values.
+func (v %[1]s) Validate() error {
+    if !v.IsValid() {
+        return types.ValidationError{
+            SchemaPath: %[2]q,
+            Keyword:    "enum",
+            Message:    fmt.Sprintf("%%v is not a permitted %[1]s value", v),
+        }
+    }
+    return nil
+}
+`, e.Name, v.g.schemaPath(e.schema)+"/enum")
+}
+
+func (v *validationEmitter) emitUnion(w io.Writer, u Union) {
+	fmt.Fprintf(w, `
+// Validate returns a types.ValidationErrors listing each of the constraints of the JSON schema that the value held
+// by the %[1]s doesn't satisfy.
+func (strct *%[1]s) Validate() error {
+    if strct == nil {
+        return nil
+    }
+    if v, ok := strct.Value.(interface{ Validate() error }); ok {
+        return v.Validate()
+    }
+    return nil
+}
+`, u.Name)
+}
+
+// emitPatterns writes the regular expressions used by the validation code.
+func (v *validationEmitter) emitPatterns(w io.Writer) {
+	if len(v.patterns) == 0 {
+		return
+	}
+	names := make(map[string]string, len(v.patterns))
+	for pattern, name := range v.patterns {
+		names[name] = pattern
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "var (")
+	for _, name := range getOrderedStringKeys(names) {
+		fmt.Fprintf(w, "    %s = regexp.MustCompile(%s)\n", name, strconv.Quote(names[name]))
+	}
+	fmt.Fprintln(w, ")")
+}
+
+// emitValue writes code which checks the value of expr, a golang expression of type typ, against the schema, and
+// adds any problems to errs. path is a golang expression which evaluates to the JSON Pointer of the value. When the
+// value isn't required, constraints aren't checked against the zero value, since it means that the value wasn't
+// present.
+func (v *validationEmitter) emitValue(w io.Writer, expr, typ string, schema *Schema, path string, required bool, depth int) {
+	if schema == nil {
+		return
+	}
+	if resolved, err := v.g.resolveReferences(schema); err == nil {
+		schema = resolved
+	}
+
+	// optional values are checked when they're set
+	if elem, ok := getWrappedType(typ); ok {
+		value := fmt.Sprintf("value%d", depth)
+		buf := new(bytes.Buffer)
+		v.emitValue(buf, value, elem, schema, path, true, depth+1)
+		if buf.Len() > 0 {
+			fmt.Fprintf(w, "    if %s, ok := %s.Get(); ok {\n", value, expr)
+			w.Write(indent(buf.Bytes()))
+			fmt.Fprintf(w, "    }\n")
+		}
+		return
+	}
+
+	// generated types validate themselves
+	if name := strings.TrimPrefix(typ, "*"); v.hasValidate(name) {
+		check := fmt.Sprintf("    errs.Merge(%s, %s.Validate())\n", path, expr)
+		if strings.HasPrefix(typ, "*") {
+			check = fmt.Sprintf("    if %s != nil {\n%s    }\n", expr, indent([]byte(check)))
+		} else if underlying := v.g.underlyingType(name); !required && isBuiltinType(underlying) {
+			check = fmt.Sprintf("    if %s != %s {\n%s    }\n", expr, zeroValue(underlying), indent([]byte(check)))
+		}
+		io.WriteString(w, check)
+		return
+	}
+
+	// pointers to values, e.g. *string, are checked when they're not nil
+	if elem := strings.TrimPrefix(typ, "*"); elem != typ && v.g.isValueType(elem) {
+		buf := new(bytes.Buffer)
+		v.emitValue(buf, "*"+expr, elem, schema, path, true, depth)
+		if buf.Len() > 0 {
+			fmt.Fprintf(w, "    if %s != nil {\n", expr)
+			w.Write(indent(buf.Bytes()))
+			fmt.Fprintf(w, "    }\n")
+		}
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	switch {
+	case typ == "string":
+		v.emitString(buf, expr, schema, path)
+	case typ == "float64" || isIntegerType(typ):
+		v.emitNumber(buf, expr, schema, path)
+	case typ == "json.Number" || typ == "*big.Int":
+		v.emitBigNumber(buf, expr, typ, schema, path)
+	case strings.HasPrefix(typ, "[]"):
+		v.emitArray(buf, expr, typ, schema, path, depth)
+	case strings.HasPrefix(typ, "map[string]"):
+		v.emitMap(buf, expr, typ, schema, path, depth)
+	}
+	if schema.Const != nil {
+		v.emitConst(buf, expr, schema, path)
+	}
+	if buf.Len() == 0 {
+		return
+	}
+	// a required *big.Int is still checked for nil, as it can't be compared when it's missing
+	if required && typ != "*big.Int" {
+		w.Write(buf.Bytes())
+		return
+	}
+	fmt.Fprintf(w, "    if %s != %s {\n", expr, zeroValue(typ))
+	w.Write(indent(buf.Bytes()))
+	fmt.Fprintf(w, "    }\n")
+}
+
+func (v *validationEmitter) emitString(w io.Writer, expr string, schema *Schema, path string) {
+	if schema.MinLength != nil || schema.MaxLength != nil {
+		v.imports["unicode/utf8"] = true
+	}
+	if schema.MinLength != nil {
+		v.emitCheck(w, fmt.Sprintf("utf8.RuneCountInString(string(%s)) < %d", expr, *schema.MinLength), path, schema,
+			"minLength", fmt.Sprintf("must be at least %d characters long", *schema.MinLength))
+	}
+	if schema.MaxLength != nil {
+		v.emitCheck(w, fmt.Sprintf("utf8.RuneCountInString(string(%s)) > %d", expr, *schema.MaxLength), path, schema,
+			"maxLength", fmt.Sprintf("must be at most %d characters long", *schema.MaxLength))
+	}
+	if schema.Pattern != "" {
+		if _, err := regexp.Compile(schema.Pattern); err != nil {
+			fmt.Fprintf(w, "    // the pattern %q is not supported by the regexp package: %v\n", schema.Pattern, err)
+			return
+		}
+		name, ok := v.patterns[schema.Pattern]
+		if !ok {
+			name = fmt.Sprintf("validationPattern%d", len(v.patterns)+1)
+			v.patterns[schema.Pattern] = name
+		}
+		v.imports["regexp"] = true
+		v.emitCheck(w, fmt.Sprintf("!%s.MatchString(string(%s))", name, expr), path, schema,
+			"pattern", fmt.Sprintf("must match the pattern %q", schema.Pattern))
+	}
+}
+
+func (v *validationEmitter) emitNumber(w io.Writer, expr string, schema *Schema, path string) {
+	if bound, ok := schema.ExclusiveMinimum(); ok {
+		v.emitCheck(w, fmt.Sprintf("float64(%s) <= %s", expr, formatFloat(bound)), path, schema,
+			"exclusiveMinimum", "must be greater than "+formatFloat(bound))
+	}
+	if schema.Minimum != nil && schema.ExclusiveMinimumValue != true {
+		v.emitCheck(w, fmt.Sprintf("float64(%s) < %s", expr, formatFloat(*schema.Minimum)), path, schema,
+			"minimum", "must be greater than or equal to "+formatFloat(*schema.Minimum))
+	}
+	if bound, ok := schema.ExclusiveMaximum(); ok {
+		v.emitCheck(w, fmt.Sprintf("float64(%s) >= %s", expr, formatFloat(bound)), path, schema,
+			"exclusiveMaximum", "must be less than "+formatFloat(bound))
+	}
+	if schema.Maximum != nil && schema.ExclusiveMaximumValue != true {
+		v.emitCheck(w, fmt.Sprintf("float64(%s) > %s", expr, formatFloat(*schema.Maximum)), path, schema,
+			"maximum", "must be less than or equal to "+formatFloat(*schema.Maximum))
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		v.imports["math"] = true
+		q := fmt.Sprintf("float64(%s) / %s", expr, formatFloat(*schema.MultipleOf))
+		v.emitCheck(w, fmt.Sprintf("math.Abs(%[1]s-math.Round(%[1]s)) > 1e-9", q), path, schema,
+			"multipleOf", "must be a multiple of "+formatFloat(*schema.MultipleOf))
+	}
+}
+
+// emitBigNumber writes the bounds checks of a json.Number or *big.Int, which are compared as big.Float values.
+func (v *validationEmitter) emitBigNumber(w io.Writer, expr, typ string, schema *Schema, path string) {
+	buf := new(bytes.Buffer)
+	if bound, ok := schema.ExclusiveMinimum(); ok {
+		v.emitCheck(buf, fmt.Sprintf("n.Cmp(big.NewFloat(%s)) <= 0", formatFloat(bound)), path, schema,
+			"exclusiveMinimum", "must be greater than "+formatFloat(bound))
+	}
+	if schema.Minimum != nil && schema.ExclusiveMinimumValue != true {
+		v.emitCheck(buf, fmt.Sprintf("n.Cmp(big.NewFloat(%s)) < 0", formatFloat(*schema.Minimum)), path, schema,
+			"minimum", "must be greater than or equal to "+formatFloat(*schema.Minimum))
+	}
+	if bound, ok := schema.ExclusiveMaximum(); ok {
+		v.emitCheck(buf, fmt.Sprintf("n.Cmp(big.NewFloat(%s)) >= 0", formatFloat(bound)), path, schema,
+			"exclusiveMaximum", "must be less than "+formatFloat(bound))
+	}
+	if schema.Maximum != nil && schema.ExclusiveMaximumValue != true {
+		v.emitCheck(buf, fmt.Sprintf("n.Cmp(big.NewFloat(%s)) > 0", formatFloat(*schema.Maximum)), path, schema,
+			"maximum", "must be less than or equal to "+formatFloat(*schema.Maximum))
+	}
+	if buf.Len() == 0 {
+		return
+	}
+	v.imports["math/big"] = true
+	// a *big.Int is checked within the block which tests that it isn't nil, see emitValue
+	if typ == "*big.Int" {
+		fmt.Fprintf(w, "    n := new(big.Float).SetInt(%s)\n", expr)
+		w.Write(buf.Bytes())
+		return
+	}
+	fmt.Fprintf(w, "    if n, ok := new(big.Float).SetString(string(%s)); ok {\n", expr)
+	w.Write(indent(buf.Bytes()))
+	fmt.Fprintf(w, "    }\n")
+}
+
+func (v *validationEmitter) emitArray(w io.Writer, expr, typ string, schema *Schema, path string, depth int) {
+	if schema.MinItems != nil {
+		v.emitCheck(w, fmt.Sprintf("len(%s) < %d", expr, *schema.MinItems), path, schema,
+			"minItems", fmt.Sprintf("must have at least %d items", *schema.MinItems))
+	}
+	if schema.MaxItems != nil {
+		v.emitCheck(w, fmt.Sprintf("len(%s) > %d", expr, *schema.MaxItems), path, schema,
+			"maxItems", fmt.Sprintf("must have at most %d items", *schema.MaxItems))
+	}
+	if schema.UniqueItems {
+		v.imports["encoding/json"] = true
+		fmt.Fprintf(w, `    {
+        seen := make(map[string]bool, len(%[1]s))
+        for _, item := range %[1]s {
+            b, err := json.Marshal(item)
+            if err != nil {
+                errs.Merge(%[2]s, err)
+                break
+            }
+            if seen[string(b)] {
+                errs.Add(%[2]s, %[3]q, "uniqueItems", "items must be unique")
+                break
+            }
+            seen[string(b)] = true
+        }
+    }
+`, expr, path, v.g.schemaPath(schema)+"/uniqueItems")
+	}
+	v.emitElements(w, expr, typ, schema.Items, path, depth)
+}
+
+func (v *validationEmitter) emitMap(w io.Writer, expr, typ string, schema *Schema, path string, depth int) {
+	if schema.MinProperties != nil {
+		v.emitCheck(w, fmt.Sprintf("len(%s) < %d", expr, *schema.MinProperties), path, schema,
+			"minProperties", fmt.Sprintf("must have at least %d properties", *schema.MinProperties))
+	}
+	if schema.MaxProperties != nil {
+		v.emitCheck(w, fmt.Sprintf("len(%s) > %d", expr, *schema.MaxProperties), path, schema,
+			"maxProperties", fmt.Sprintf("must have at most %d properties", *schema.MaxProperties))
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.AdditionalPropertiesBool == nil {
+		v.emitElements(w, expr, typ, (*Schema)(schema.AdditionalProperties), path, depth)
+	}
+}
+
+// emitElements writes a loop which checks each of the elements of a slice or map against the schema.
+func (v *validationEmitter) emitElements(w io.Writer, expr, typ string, schema *Schema, path string, depth int) {
+	key, item := fmt.Sprintf("k%d", depth), fmt.Sprintf("item%d", depth)
+	elemType := strings.TrimPrefix(typ, "[]")
+	elemPath := fmt.Sprintf(`%s + "/" + strconv.Itoa(%s)`, path, key)
+	if path == `""` {
+		elemPath = fmt.Sprintf(`"/" + strconv.Itoa(%s)`, key)
+	}
+	imp := "strconv"
+	if strings.HasPrefix(typ, "map[string]") {
+		elemType = strings.TrimPrefix(typ, "map[string]")
+		elemPath = fmt.Sprintf("types.JoinPointer(%s, %s)", path, key)
+		imp = typesImport
+	}
+	buf := new(bytes.Buffer)
+	v.emitValue(buf, item, elemType, schema, elemPath, true, depth+1)
+	if buf.Len() == 0 {
+		return
+	}
+	v.imports[imp] = true
+	fmt.Fprintf(w, "    for %s, %s := range %s {\n", key, item, expr)
+	w.Write(indent(buf.Bytes()))
+	fmt.Fprintf(w, "    }\n")
+}
+
+func (v *validationEmitter) emitConst(w io.Writer, expr string, schema *Schema, path string) {
+	b, err := json.Marshal(schema.Const)
+	if err != nil {
+		return
+	}
+	v.imports["encoding/json"] = true
+	v.emitCheck(w, fmt.Sprintf("b, err := json.Marshal(%s); err != nil || string(b) != %s", expr, strconv.Quote(string(b))),
+		path, schema, "const", "must be "+string(b))
+}
+
+// emitCheck writes code which adds an error to errs when the condition is true.
+func (v *validationEmitter) emitCheck(w io.Writer, condition, path string, schema *Schema, keyword, message string) {
+	fmt.Fprintf(w, `    if %s {
+        errs.Add(%s, %q, %q, %q)
+    }
+`, condition, path, v.g.schemaPath(schema)+"/"+keyword, keyword, message)
+}
+
+// schemaPath returns the location of the schema for use in validation errors, e.g. "#/definitions/address".
+func (g *Generator) schemaPath(schema *Schema) string {
+	if schema == nil {
+		return "#"
+	}
+	return g.resolver.GetPath(schema)
+}
+
+// jsonPointer returns the JSON Pointer of a property of the root value, e.g. "/name".
+func jsonPointer(property string) string {
+	return "/" + escapePointerToken(property)
+}
+
+func requiredMessage(property string) string {
+	return strconv.Quote(property) + " is required but was not present"
+}
+
+// hasValidate returns true when a Validate method is generated for the type.
+func (v *validationEmitter) hasValidate(typ string) bool {
+	if a, ok := v.g.Aliases[typ]; ok {
+		return canHaveMethods(a.Type)
+	}
+	return v.g.isGeneratedType(typ)
+}
+
+// canHaveMethods returns true when methods can be declared on a named type with the underlying type.
+func canHaveMethods(underlying string) bool {
+	return underlying != "interface{}" && !strings.HasPrefix(underlying, "*")
+}
+
+// isGeneratedType returns true when the type has been generated from the schema, e.g. "Address".
+func (g *Generator) isGeneratedType(typ string) bool {
+	_, isStruct := g.Structs[typ]
+	_, isAlias := g.Aliases[typ]
+	_, isEnum := g.Enums[typ]
+	_, isUnion := g.Unions[typ]
+	_, isTuple := g.Tuples[typ]
+	return isStruct || isAlias || isEnum || isUnion || isTuple
+}
+
+// underlyingType returns the golang type underlying a generated type, e.g. "string" for an enum of strings.
+func (g *Generator) underlyingType(typ string) string {
+	if e, ok := g.Enums[typ]; ok {
+		if e.Type == "" {
+			return "string"
+		}
+		return e.Type
+	}
+	if a, ok := g.Aliases[typ]; ok {
+		return a.Type
+	}
+	return typ
+}
+
+// isNillable returns true when the zero value of the type is nil.
+func isNillable(typ string) bool {
+	return typ == "interface{}" || typ == "net.IP" || strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[")
+}
+
+func zeroValue(typ string) string {
+	if isNillable(typ) {
+		return "nil"
+	}
+	switch typ {
+	case "string", "json.Number":
+		return `""`
+	case "bool":
+		return "false"
+	}
+	// types from other packages, e.g. time.Time, are structs, and their literals are parenthesised so that they can be
+	// used in an if statement
+	if strings.Contains(typ, ".") {
+		return "(" + typ + "{})"
+	}
+	return "0"
+}
+
+// underlyingValue returns an expression which converts expr to the builtin type typ, so that it can be used in
+// comparisons.
+func underlyingValue(expr, typ string) string {
+	if isBuiltinType(typ) {
+		return typ + "(" + expr + ")"
+	}
+	return expr
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func indent(b []byte) []byte {
+	lines := strings.SplitAfter(string(b), "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = "    " + l
+		}
+	}
+	return []byte(strings.Join(lines, ""))
+}
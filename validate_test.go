@@ -0,0 +1,62 @@
+package generate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestThatUnsupportedPatternsAreNotValidated(t *testing.T) {
+	g := New()
+	v := newValidationEmitter(g, make(map[string]bool))
+	buf := new(bytes.Buffer)
+	v.emitValue(buf, "strct.Name", "string", &Schema{Pattern: "^(?!abc)"}, `"name"`, true, 0)
+
+	if !strings.Contains(buf.String(), "is not supported by the regexp package") {
+		t.Errorf("expected a comment explaining that the pattern isn't supported, got %q", buf.String())
+	}
+	if len(v.patterns) != 0 || v.imports["regexp"] {
+		t.Error("expected the pattern not to be compiled by the generated code")
+	}
+}
+
+func TestThatOptionalValuesAreOnlyValidatedWhenPresent(t *testing.T) {
+	tests := []struct {
+		typ      string
+		schema   *Schema
+		expected string
+	}{
+		{
+			typ:      "string",
+			schema:   &Schema{MinLength: intPtr(1)},
+			expected: `if strct.Value != "" {`,
+		},
+		{
+			typ:      "int",
+			schema:   &Schema{Minimum: float64Ptr(1)},
+			expected: "if strct.Value != 0 {",
+		},
+		{
+			typ:      "[]string",
+			schema:   &Schema{MinItems: intPtr(1)},
+			expected: "if strct.Value != nil {",
+		},
+	}
+
+	for _, test := range tests {
+		v := newValidationEmitter(New(), make(map[string]bool))
+		buf := new(bytes.Buffer)
+		v.emitValue(buf, "strct.Value", test.typ, test.schema, `"value"`, false, 0)
+		if !strings.HasPrefix(strings.TrimSpace(buf.String()), test.expected) {
+			t.Errorf("%s: expected the check to start with %q, got %q", test.typ, test.expected, buf.String())
+		}
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
@@ -0,0 +1,109 @@
+package generate
+
+import (
+	"strconv"
+)
+
+// SubSchema is a schema nested directly within another, e.g. a property.
+type SubSchema struct {
+	// Keyword which holds the schema, e.g. "properties" or "allOf". It's empty for the schema that Walk starts from.
+	Keyword string
+	// Key of the schema within the keyword, e.g. the name of a property, or the index within an allOf. It's empty
+	// for keywords which hold a single schema, e.g. "not".
+	Key    string
+	Schema *Schema
+}
+
+// PathElement returns the location of the sub-schema within its parent, e.g. "properties/name". The key is escaped
+// as a JSON Pointer reference token, e.g. "properties/a~1b" for the key "a/b".
+func (s SubSchema) PathElement() string {
+	if s.Key == "" {
+		return s.Keyword
+	}
+	return s.Keyword + "/" + escapePointerToken(s.Key)
+}
+
+// isNamed returns true when the key of the sub-schema names the instance it describes, e.g. a property, so that it
+// can be used to name the generated type.
+func (s SubSchema) isNamed() bool {
+	return s.Keyword == "definitions" || s.Keyword == "$defs" || s.Keyword == "properties"
+}
+
+// SubSchemas returns the schemas nested directly within the schema, in a stable order.
+func (schema *Schema) SubSchemas() []SubSchema {
+	var rv []SubSchema
+	keyed := func(keyword string, m map[string]*Schema) {
+		for _, k := range getOrderedSchemaKeys(m) {
+			rv = append(rv, SubSchema{Keyword: keyword, Key: k, Schema: m[k]})
+		}
+	}
+	single := func(keyword string, s *Schema) {
+		if s != nil {
+			rv = append(rv, SubSchema{Keyword: keyword, Schema: s})
+		}
+	}
+	indexed := func(keyword string, a []*Schema) {
+		for i, s := range a {
+			rv = append(rv, SubSchema{Keyword: keyword, Key: strconv.Itoa(i), Schema: s})
+		}
+	}
+
+	keyed("definitions", schema.Definitions)
+	keyed("$defs", schema.Defs)
+	keyed("properties", schema.Properties)
+	single("additionalProperties", (*Schema)(schema.AdditionalProperties))
+	single("unevaluatedProperties", (*Schema)(schema.UnevaluatedProperties))
+	if schema.itemsArray {
+		indexed("items", schema.PrefixItems)
+		single("additionalItems", schema.Items)
+	} else {
+		indexed("prefixItems", schema.PrefixItems)
+		single("items", schema.Items)
+	}
+	indexed("allOf", schema.AllOf)
+	indexed("anyOf", schema.AnyOf)
+	indexed("oneOf", schema.OneOf)
+	single("not", schema.Not)
+	single("if", schema.If)
+	single("then", schema.Then)
+	single("else", schema.Else)
+	keyed("patternProperties", schema.PatternProperties)
+	keyed("dependencies", schema.dependencySchemas())
+	keyed("dependentSchemas", schema.DependentSchemas)
+	single("propertyNames", schema.PropertyNames)
+	single("contains", schema.Contains)
+	return rv
+}
+
+// A Visitor's Visit method is called by Walk for each schema. If the result w is not nil, Walk visits each of the
+// sub-schemas of the schema with w, so that a Visitor can pass state, e.g. the parent schema, to the sub-schemas.
+type Visitor interface {
+	Visit(s SubSchema) (w Visitor)
+}
+
+// VisitorFunc is a Visitor which visits the sub-schemas of a schema when the function returns true.
+type VisitorFunc func(s SubSchema) bool
+
+// Visit calls f(s).
+func (f VisitorFunc) Visit(s SubSchema) Visitor {
+	if f(s) {
+		return f
+	}
+	return nil
+}
+
+// Walk traverses the schema and its sub-schemas, depth first, in the order returned by SubSchemas. Each of the
+// passes over a schema, e.g. those which set the Parent and PathElement of each schema, uses Walk, so that all of
+// the keywords holding sub-schemas are traversed in the same way.
+func Walk(v Visitor, schema *Schema) {
+	walk(v, SubSchema{Schema: schema})
+}
+
+func walk(v Visitor, s SubSchema) {
+	if v = v.Visit(s); v == nil {
+		return
+	}
+	for _, sub := range s.Schema.SubSchemas() {
+		walk(v, sub)
+	}
+}
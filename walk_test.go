@@ -0,0 +1,74 @@
+package generate
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestThatWalkVisitsEverySubSchema(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#",
+		"properties": { "a": { "not": { "type": "string" } } },
+		"allOf": [ { "if": { "type": "object" }, "then": { "required": ["a"] }, "else": { "anyOf": [ { "type": "null" } ] } } ],
+		"patternProperties": { "^x-": { "oneOf": [ { "type": "integer" } ] } } }`,
+		&url.URL{Scheme: "file", Path: "/root.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	resolver := NewRefResolver(nil)
+	Walk(VisitorFunc(func(s SubSchema) bool {
+		paths = append(paths, resolver.GetPath(s.Schema))
+		return s.Keyword != "patternProperties"
+	}), root)
+	expected := []string{
+		"#",
+		"#/properties/a",
+		"#/properties/a/not",
+		"#/allOf/0",
+		"#/allOf/0/if",
+		"#/allOf/0/then",
+		"#/allOf/0/else",
+		"#/allOf/0/else/anyOf/0",
+		"#/patternProperties/^x-",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestThatIDsWithinCombinatorsAreMapped(t *testing.T) {
+	root, err := Parse(`{ "$schema": "http://json-schema.org/draft-07/schema#", "$id": "http://example.com/root.json",
+		"title": "Root",
+		"properties": { "a": { "$ref": "sub/a.json" }, "b": { "$ref": "#/not/anyOf/0" } },
+		"allOf": [ { "$id": "sub/a.json", "title": "A", "properties": { "c": { "$ref": "c.json" } } } ],
+		"oneOf": [ { "$id": "sub/c.json", "type": "integer" } ],
+		"not": { "anyOf": [ { "title": "B", "type": "object" } ] } }`,
+		&url.URL{Scheme: "file", Path: "/root.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := NewRefResolver([]*Schema{root})
+	if err := resolver.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for _, uri := range []string{
+		"http://example.com/sub/a.json",
+		"http://example.com/sub/a.json#/properties/c",
+		"http://example.com/sub/c.json",
+		"http://example.com/root.json#/allOf/0/properties/c",
+		"http://example.com/root.json#/not/anyOf/0",
+	} {
+		if _, ok := resolver.pathToSchema[uri]; !ok {
+			t.Errorf("expected %s to be mapped", uri)
+		}
+	}
+
+	// the reference within the allOf resolves against the $id of the allOf, rather than of the root
+	c := root.AllOf[0].Properties["c"]
+	if resolved, err := resolver.GetSchemaByReference(c); err != nil || resolved != root.OneOf[0] {
+		t.Errorf("expected %s to resolve to the oneOf, got %v, %v", c.Reference, resolved, err)
+	}
+}